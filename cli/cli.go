@@ -0,0 +1,209 @@
+// Package cli is the command line of Wire-Jacket checking the modules
+// of a config offline, without creating them.
+//
+// It needs the injectors of the app, so it runs in a main importing
+// them. The wirejacket command generates the main and runs it, or you
+// can write your own.
+//
+//	package main
+//
+//	import (
+//		"github.com/bang9211/wire-jacket/cli"
+//		"example.com/app/wire"
+//	)
+//
+//	func main() {
+//		cli.Main(wire.Injectors, wire.EagerInjectors)
+//	}
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	wirejacket "github.com/bang9211/wire-jacket"
+)
+
+// Exit codes of Run.
+const (
+	ExitOK      = 0
+	ExitFailure = 1
+	ExitUsage   = 2
+)
+
+var errUsage = errors.New("invalid usage")
+
+// Main runs the command of os.Args with injectors and eagerInjectors,
+// then exits with its exit code.
+func Main(injectors, eagerInjectors map[string]interface{}) {
+	os.Exit(Run(os.Args[1:], os.Stdout, os.Stderr, injectors, eagerInjectors))
+}
+
+// Run runs the command of args with injectors and eagerInjectors.
+// It returns ExitFailure if the command found problems or the config
+// file doesn't exist, and ExitUsage if args are invalid.
+//
+// The config file of --config is loaded by viperjacket once per
+// process, so the later Runs should have the same --config, or they
+// return ExitFailure. The environment variables override the config
+// like in the app.
+func Run(
+	args []string,
+	stdout, stderr io.Writer,
+	injectors, eagerInjectors map[string]interface{}) int {
+	fs := flag.NewFlagSet("wirejacket", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { WriteUsage(stderr, fs) }
+	flags := &Flags{}
+	flags.Register(fs)
+
+	if len(args) == 0 {
+		fs.Usage()
+		return ExitUsage
+	}
+	command := args[0]
+	if err := fs.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return ExitOK
+		}
+		return ExitUsage
+	}
+	if !IsCommand(command) {
+		fmt.Fprintf(stderr, "unknown command(%s)\n", command)
+		fs.Usage()
+		return ExitUsage
+	}
+	if flags.Config != "" {
+		// viperjacket only logs the missing config file, and the
+		// default values would pass the checks.
+		if _, err := os.Stat(flags.Config); err != nil {
+			fmt.Fprintf(stderr, "failed to read config : %s\n", err)
+			return ExitFailure
+		}
+	}
+
+	if err := loadConfig(flags.Config); err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitFailure
+	}
+
+	wj := wirejacket.NewWithServiceName(flags.Service).
+		SetInjectors(injectors).
+		SetEagerInjectors(eagerInjectors)
+	if flags.Modules != "" {
+		wj.SetActivatingModules(strings.Fields(flags.Modules))
+	}
+
+	var err error
+	switch command {
+	case "validate":
+		err = validate(wj, stdout)
+	case "plan":
+		err = plan(wj, stdout, flags.Format)
+	case "graph":
+		err = graph(wj, stdout, flags.Format)
+	}
+	if errors.Is(err, errUsage) {
+		fmt.Fprintln(stderr, err)
+		fs.Usage()
+		return ExitUsage
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitFailure
+	}
+	return ExitOK
+}
+
+// defaultConfigFile is the config file of viperjacket without --config.
+const defaultConfigFile = "app.conf"
+
+var loadConfigOnce sync.Once
+
+// loadConfig loads the config of viperjacket from configFile, or
+// app.conf if empty. viperjacket loads its config once per process
+// from --config of os.Args and exits on the flags it doesn't know, so
+// os.Args has only --config while the first Run loads it, and later
+// Runs don't touch it. A later Run with another config fails, the
+// loaded config can't be replaced.
+func loadConfig(configFile string) error {
+	if configFile == "" {
+		configFile = defaultConfigFile
+	}
+	loadConfigOnce.Do(func() {
+		args := os.Args
+		defer func() { os.Args = args }()
+		os.Args = []string{args[0], "--config", configFile}
+		wirejacket.GetConfig()
+	})
+
+	loaded := wirejacket.GetConfig().GetString("config", defaultConfigFile)
+	if !samePath(loaded, configFile) {
+		return fmt.Errorf("can't load config(%s), config(%s) is already loaded in this process",
+			configFile, loaded)
+	}
+	return nil
+}
+
+// samePath reports whether path1 and path2 are the same file path.
+func samePath(path1, path2 string) bool {
+	abs1, err1 := filepath.Abs(path1)
+	abs2, err2 := filepath.Abs(path2)
+	if err1 != nil || err2 != nil {
+		return filepath.Clean(path1) == filepath.Clean(path2)
+	}
+	return abs1 == abs2
+}
+
+func validate(wj *wirejacket.WireJacket, stdout io.Writer) error {
+	if err := wj.Validate(); err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, "ok")
+	return nil
+}
+
+func plan(wj *wirejacket.WireJacket, stdout io.Writer, format string) error {
+	p, err := wj.Plan()
+	if err != nil {
+		return err
+	}
+	switch format {
+	case "", "text":
+		fmt.Fprint(stdout, p)
+	case "json":
+		data, err := p.JSON()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, string(data))
+	default:
+		return fmt.Errorf("%w : unknown format(%s) of plan", errUsage, format)
+	}
+	return nil
+}
+
+func graph(wj *wirejacket.WireJacket, stdout io.Writer, format string) error {
+	g := wj.Graph()
+	switch format {
+	case "", "dot":
+		fmt.Fprint(stdout, g.DOT())
+	case "mermaid":
+		fmt.Fprint(stdout, g.Mermaid())
+	case "json":
+		data, err := g.JSON()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, string(data))
+	default:
+		return fmt.Errorf("%w : unknown format(%s) of graph", errUsage, format)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/bang9211/wire-jacket/internal/mockup"
+	"github.com/stretchr/testify/assert"
+)
+
+func run(args ...string) (int, string, string) {
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	args = append(args, "--config", "../test.json", "--service", "test_example")
+	code := Run(args, stdout, stderr, mockup.Injectors, mockup.EagerInjectors)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRunValidate(t *testing.T) {
+	code, stdout, stderr := run("validate")
+	assert.Equal(t, ExitOK, code, stderr)
+	assert.Equal(t, "ok\n", stdout)
+
+	code, stdout, stderr = run("validate", "--modules", "mockup_blockchain mockup_explorerserver")
+	assert.Equal(t, ExitFailure, code)
+	assert.Empty(t, stdout)
+	assert.Contains(t, stderr, "problems found")
+	assert.Contains(t, stderr, "mockup.Database")
+	assert.Contains(t, stderr, "mockup_restapiserver")
+}
+
+func TestRunPlan(t *testing.T) {
+	code, stdout, stderr := run("plan")
+	assert.Equal(t, ExitOK, code, stderr)
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	modules := []string{}
+	for _, line := range lines[1:] {
+		modules = append(modules, strings.Fields(line)[0])
+	}
+	assert.Equal(t, []string{
+		"mockup_database", "mockup_blockchain",
+		"mockup_explorerserver", "mockup_restapiserver",
+	}, modules)
+
+	code, stdout, stderr = run("plan", "--format", "json")
+	assert.Equal(t, ExitOK, code, stderr)
+	decoded := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal([]byte(stdout), &decoded))
+	assert.Len(t, decoded["steps"], 4)
+}
+
+func TestRunGraph(t *testing.T) {
+	code, stdout, stderr := run("graph")
+	assert.Equal(t, ExitOK, code, stderr)
+	assert.True(t, strings.HasPrefix(stdout, "digraph wirejacket {"))
+
+	code, stdout, stderr = run("graph", "--format", "mermaid")
+	assert.Equal(t, ExitOK, code, stderr)
+	assert.True(t, strings.HasPrefix(stdout, "graph LR"))
+
+	code, stdout, stderr = run("graph", "--format", "json")
+	assert.Equal(t, ExitOK, code, stderr)
+	assert.Contains(t, stdout, `"from": "mockup_blockchain"`)
+}
+
+func TestRunUsage(t *testing.T) {
+	code, _, stderr := run("deploy")
+	assert.Equal(t, ExitUsage, code)
+	assert.Contains(t, stderr, "unknown command(deploy)")
+	assert.Contains(t, stderr, "usage:")
+
+	code, _, stderr = run("graph", "--format", "svg")
+	assert.Equal(t, ExitUsage, code)
+	assert.Contains(t, stderr, "unknown format(svg)")
+
+	code, _, _ = run("plan", "--unknown")
+	assert.Equal(t, ExitUsage, code)
+
+	stderr2 := &bytes.Buffer{}
+	code = Run(nil, &bytes.Buffer{}, stderr2, mockup.Injectors, mockup.EagerInjectors)
+	assert.Equal(t, ExitUsage, code)
+	assert.Contains(t, stderr2.String(), "usage:")
+}
+
+func TestRunMissingConfig(t *testing.T) {
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	code := Run([]string{"validate", "--config", "../missing.json"},
+		stdout, stderr, mockup.Injectors, mockup.EagerInjectors)
+	assert.Equal(t, ExitFailure, code)
+	assert.Empty(t, stdout.String())
+	assert.Contains(t, stderr.String(), "missing.json")
+}
+
+func TestRunKeepsArgs(t *testing.T) {
+	args := os.Args
+	code, _, stderr := run("plan")
+	assert.Equal(t, ExitOK, code, stderr)
+	assert.Equal(t, args, os.Args)
+}
+
+func TestFlagsArgs(t *testing.T) {
+	flags := &Flags{Config: "app.conf", Format: "json"}
+	assert.Equal(t, []string{"--config", "app.conf", "--format", "json"}, flags.Args())
+	assert.Empty(t, (&Flags{}).Args())
+}
+
+func TestRunOtherConfig(t *testing.T) {
+	code, _, stderr := run("validate")
+	assert.Equal(t, ExitOK, code, stderr)
+
+	// the config is loaded once per process.
+	stdout, stderr2 := &bytes.Buffer{}, &bytes.Buffer{}
+	code = Run([]string{"validate", "--config", "../app.conf"},
+		stdout, stderr2, mockup.Injectors, mockup.EagerInjectors)
+	assert.Equal(t, ExitFailure, code)
+	assert.Empty(t, stdout.String())
+	assert.Contains(t, stderr2.String(), "already loaded")
+
+	stderr2.Reset()
+	code = Run([]string{"validate", "--config", "./../test.json", "--service", "test_example"},
+		&bytes.Buffer{}, stderr2, mockup.Injectors, mockup.EagerInjectors)
+	assert.Equal(t, ExitOK, code, stderr2.String())
+}
@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+)
+
+// Commands is the usage of the commands of Run.
+const Commands = `  validate  check every activating module can be wired
+  plan      print the modules DoWire would create, in order
+  graph     print the dependency graph
+`
+
+// IsCommand reports whether command is a command of Run.
+func IsCommand(command string) bool {
+	switch command {
+	case "validate", "plan", "graph":
+		return true
+	}
+	return false
+}
+
+// WriteUsage writes the usage of wirejacket with Commands, the extra
+// commands and the flags of fs.
+func WriteUsage(w io.Writer, fs *flag.FlagSet, extraCommands ...string) {
+	fmt.Fprint(w, "usage: wirejacket <command> [flags]\n\ncommands:\n")
+	fmt.Fprint(w, Commands)
+	for _, command := range extraCommands {
+		fmt.Fprintln(w, command)
+	}
+	fmt.Fprint(w, "\nflags:\n")
+	fs.PrintDefaults()
+}
+
+// Flags are the flags of the commands of Run.
+type Flags struct {
+	Config  string
+	Service string
+	Modules string
+	Format  string
+}
+
+// Register defines the flags in fs.
+func (f *Flags) Register(fs *flag.FlagSet) {
+	fs.StringVar(&f.Config, "config", "", "config file, app.conf if empty")
+	fs.StringVar(&f.Service, "service", "", "service name, reads '{service}_modules' if set")
+	fs.StringVar(&f.Modules, "modules", "", "space separated modules to activate instead of the config")
+	fs.StringVar(&f.Format, "format", "", "output format, text or json for plan, dot, mermaid or json for graph")
+}
+
+// Args returns the flags set, to pass them to Run.
+func (f *Flags) Args() []string {
+	args := []string{}
+	for _, flag := range []struct{ name, value string }{
+		{"config", f.Config},
+		{"service", f.Service},
+		{"modules", f.Modules},
+		{"format", f.Format},
+	} {
+		if flag.value != "" {
+			args = append(args, "--"+flag.name, flag.value)
+		}
+	}
+	return args
+}
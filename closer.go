@@ -0,0 +1,201 @@
+package wirejacket
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+)
+
+// CloseTimeoutKeySuffix is the suffix of the config key of the
+// timeout to close a module, '{module_name}_close_timeout'.
+const CloseTimeoutKeySuffix = "_close_timeout"
+
+// CloserFunc closes module. It is registered with SetCloser or
+// SetCloserByType for the modules having no way WireJacket knows
+// to close them.
+type CloserFunc func(ctx context.Context, module interface{}) error
+
+// typeCloser is a CloserFunc for the modules of moduleType.
+type typeCloser struct {
+	moduleType reflect.Type
+	closer     CloserFunc
+}
+
+// closingModule is a created module with the way to close it.
+type closingModule struct {
+	name    string
+	module  interface{}
+	closer  CloserFunc
+	cleanup func()
+	timeout time.Duration
+}
+
+// SetCloser sets closer to close the module of moduleName.
+// It takes precedence over the closers by type and the methods
+// of the module.
+func (wj *WireJacket) SetCloser(moduleName string, closer CloserFunc) *WireJacket {
+	wj.mu.Lock()
+	defer wj.mu.Unlock()
+	wj.closers[moduleName] = closer
+	return wj
+}
+
+// SetCloserByType sets closer to close the modules of moduleType.
+// moduleType is nil pointer of the type, like GetModuleByType.
+// e.g. (*MyInterface)(nil) or (**MyStruct)(nil)
+// A module is closed by the first closer its type is assignable to,
+// in order of setting.
+func (wj *WireJacket) SetCloserByType(moduleType interface{}, closer CloserFunc) *WireJacket {
+	wj.mu.Lock()
+	defer wj.mu.Unlock()
+	t := reflect.TypeOf(moduleType)
+	if t == nil || t.Kind() != reflect.Ptr {
+		panic(fmt.Sprintf("moduleType(%T) should be nil pointer of the type", moduleType))
+	}
+	wj.typeClosers = append(wj.typeClosers, typeCloser{moduleType: t.Elem(), closer: closer})
+	return wj
+}
+
+// closerOf returns the CloserFunc to close module of moduleName.
+// It is the closer set by name, by type or the closer found in the
+// methods of module in that order. It returns nil if module has none.
+// wj.mu should be held.
+func (wj *WireJacket) closerOf(moduleName string, module interface{}) CloserFunc {
+	if closer, ok := wj.closers[moduleName]; ok {
+		return closer
+	}
+	if moduleType := reflect.TypeOf(module); moduleType != nil {
+		for _, tc := range wj.typeClosers {
+			if moduleType.AssignableTo(tc.moduleType) {
+				return tc.closer
+			}
+		}
+	}
+	return methodCloser(module)
+}
+
+// methodCloser returns the CloserFunc calling the close method of
+// module. The methods are searched in order of io.Closer(Module),
+// Shutdown(context.Context) error, Close() and Stop().
+// It returns nil if module has none of them.
+func methodCloser(module interface{}) CloserFunc {
+	switch module.(type) {
+	case io.Closer:
+		return func(ctx context.Context, module interface{}) error {
+			return module.(io.Closer).Close()
+		}
+	case interface{ Shutdown(context.Context) error }:
+		return func(ctx context.Context, module interface{}) error {
+			return module.(interface{ Shutdown(context.Context) error }).Shutdown(ctx)
+		}
+	case interface{ Close() }:
+		return func(ctx context.Context, module interface{}) error {
+			module.(interface{ Close() }).Close()
+			return nil
+		}
+	case interface{ Stop() }:
+		return func(ctx context.Context, module interface{}) error {
+			module.(interface{ Stop() }).Stop()
+			return nil
+		}
+	}
+	return nil
+}
+
+// closingModuleOf returns the closingModule of moduleName.
+// wj.mu should be held.
+func (wj *WireJacket) closingModuleOf(moduleName string) *closingModule {
+	module := wj.modules[moduleName]
+	return &closingModule{
+		name:    moduleName,
+		module:  module,
+		closer:  wj.closerOf(moduleName, module),
+		cleanup: wj.cleanups[moduleName],
+		timeout: wj.config.GetDuration(moduleName+CloseTimeoutKeySuffix, 0),
+	}
+}
+
+// closeModules closes closingModules in order until ctx is done.
+// It returns the errors of the modules failed, abandoned or skipped.
+func (wj *WireJacket) closeModules(ctx context.Context, closingModules []*closingModule) []error {
+	closeErrors := []error{}
+	for i, cm := range closingModules {
+		if ctx.Err() != nil {
+			for _, skipped := range closingModules[i:] {
+				err := fmt.Errorf("module(%s) is not closed : %w", skipped.name, ctx.Err())
+				wj.emitClosed(skipped, 0, err)
+				closeErrors = append(closeErrors, err)
+			}
+			break
+		}
+		start := time.Now()
+		err := cm.closeWithin(ctx)
+		wj.emitClosed(cm, time.Since(start), err)
+		if err != nil {
+			closeErrors = append(closeErrors, err)
+		}
+	}
+	return closeErrors
+}
+
+// emitClosed emits and logs EventModuleClosed, or EventModuleCloseFailed
+// if err is not nil.
+func (wj *WireJacket) emitClosed(cm *closingModule, duration time.Duration, err error) {
+	moduleType := fmt.Sprintf("%T", cm.module)
+	if err != nil {
+		wj.emit(Event{Type: EventModuleCloseFailed, Module: cm.name, Duration: duration, Err: err})
+		wj.log().Error("failed to close module",
+			"module", cm.name, "type", moduleType, "duration", duration, "error", err)
+		return
+	}
+	wj.emit(Event{Type: EventModuleClosed, Module: cm.name, Duration: duration})
+	wj.log().Debug("closed module", "module", cm.name, "type", moduleType, "duration", duration)
+}
+
+// closeWithin closes the module in its timeout and the deadline of ctx.
+// If it doesn't return in time, it is abandoned in the background.
+func (cm *closingModule) closeWithin(ctx context.Context) error {
+	if cm.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cm.timeout)
+		defer cancel()
+	}
+	if ctx.Done() == nil {
+		return cm.close(ctx)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("failed to close module(%s) : closer panicked : %v", cm.name, r)
+			}
+		}()
+		done <- cm.close(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("failed to close module(%s) : %w : closer didn't return",
+			cm.name, ctx.Err())
+	}
+}
+
+// close closes the module with its closer if exists, then calls
+// cleanup returned by its injector if exists.
+func (cm *closingModule) close(ctx context.Context) error {
+	var err error
+	if cm.closer != nil {
+		if closeErr := cm.closer(ctx, cm.module); closeErr != nil {
+			err = fmt.Errorf("failed to close module(%s) : %w", cm.name, closeErr)
+		}
+	}
+	if cm.cleanup != nil {
+		cm.cleanup()
+	}
+	return err
+}
@@ -0,0 +1,180 @@
+package wirejacket
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testShutdowner struct{ closeRecorder }
+
+func (ts *testShutdowner) Shutdown(ctx context.Context) error {
+	if ctx == nil {
+		return errors.New("nil context")
+	}
+	return ts.closeRecorder.Close()
+}
+
+type testVoidCloser struct{ closeRecorder }
+
+func (tvc *testVoidCloser) Close() { tvc.closeRecorder.Close() }
+
+type testStopper struct{ closeRecorder }
+
+func (ts *testStopper) Stop() { ts.closeRecorder.Close() }
+
+func TestCloseByMethods(t *testing.T) {
+	resetClosedModuleNames()
+	wj := New()
+	wj.AddEagerInjector("test_shutdowner", func() *testShutdowner {
+		return &testShutdowner{closeRecorder{name: "test_shutdowner"}}
+	})
+	wj.AddEagerInjector("test_void_closer", func() *testVoidCloser {
+		return &testVoidCloser{closeRecorder{name: "test_void_closer"}}
+	})
+	wj.AddEagerInjector("test_stopper", func() *testStopper {
+		return &testStopper{closeRecorder{name: "test_stopper"}}
+	})
+	wj.SetActivatingModules([]string{"test_shutdowner", "test_void_closer", "test_stopper"})
+
+	err := wj.DoWire()
+	assert.NoError(t, err, "Failed to DoWire()")
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+	assert.Equal(t, []string{"test_stopper", "test_void_closer", "test_shutdowner"},
+		getClosedModuleNames())
+}
+
+func TestSetCloser(t *testing.T) {
+	resetClosedModuleNames()
+	closed := []string{}
+	wj := New()
+	wj.AddEagerInjector("test_database", injectTestDatabase)
+	wj.AddEagerInjector("test_client", func() *testClient { return &testClient{} })
+	wj.SetActivatingModules([]string{"test_database", "test_client"})
+	wj.SetCloser("test_database", func(ctx context.Context, module interface{}) error {
+		closed = append(closed, "test_database")
+		return nil
+	})
+	wj.SetCloserByType((**testClient)(nil), func(ctx context.Context, module interface{}) error {
+		module.(*testClient).released = true
+		closed = append(closed, "test_client")
+		return nil
+	})
+
+	err := wj.DoWire()
+	assert.NoError(t, err, "Failed to DoWire()")
+	client := wj.GetModule("test_client").(*testClient)
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+	assert.Equal(t, []string{"test_client", "test_database"}, closed)
+	assert.True(t, client.released)
+	// the closer by name takes precedence over Close() of the module.
+	assert.Empty(t, getClosedModuleNames())
+}
+
+func TestSetCloserByInterfaceType(t *testing.T) {
+	resetClosedModuleNames()
+	closed := []string{}
+	wj := New()
+	wj.AddEagerInjector("test_database", injectTestDatabase)
+	wj.SetActivatingModules([]string{"test_database"})
+	wj.SetCloserByType((*testDatabase)(nil), func(ctx context.Context, module interface{}) error {
+		closed = append(closed, module.(testDatabase).Query())
+		return nil
+	})
+
+	err := wj.DoWire()
+	assert.NoError(t, err, "Failed to DoWire()")
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+	assert.Equal(t, []string{"test_database"}, closed)
+}
+
+func TestSetCloserByTypeInvalidType(t *testing.T) {
+	wj := New()
+	assert.Panics(t, func() {
+		wj.SetCloserByType(testClient{}, func(ctx context.Context, module interface{}) error {
+			return nil
+		})
+	})
+}
+
+func TestCloserError(t *testing.T) {
+	errTestClose := errors.New("test close error")
+	cm := &closingModule{
+		name:   "test_client",
+		module: &testClient{},
+		closer: func(ctx context.Context, module interface{}) error {
+			return errTestClose
+		},
+	}
+
+	err := cm.close(context.Background())
+	assert.True(t, errors.Is(err, errTestClose))
+	assert.Contains(t, err.Error(), "test_client")
+}
+
+// testHangingCloser doesn't return from Shutdown until release is closed.
+type testHangingCloser struct {
+	release chan struct{}
+}
+
+func (thc *testHangingCloser) Shutdown(ctx context.Context) error {
+	<-thc.release
+	return nil
+}
+
+func TestCloseContextModuleTimeout(t *testing.T) {
+	t.Setenv("TEST_HANGING"+strings.ToUpper(CloseTimeoutKeySuffix), "10ms")
+	resetClosedModuleNames()
+	hanging := &testHangingCloser{release: make(chan struct{})}
+	defer close(hanging.release)
+	wj := New()
+	wj.AddEagerInjector("test_database", injectTestDatabase)
+	wj.AddEagerInjector("test_hanging", func() *testHangingCloser { return hanging })
+	wj.SetActivatingModules([]string{"test_database", "test_hanging"})
+
+	err := wj.DoWire()
+	assert.NoError(t, err, "Failed to DoWire()")
+
+	err = wj.CloseContext(context.Background())
+	var closeErr *CloseError
+	assert.True(t, errors.As(err, &closeErr))
+	assert.Len(t, closeErr.Errors, 1)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Contains(t, err.Error(), "test_hanging")
+	// the module after the abandoned one is closed.
+	assert.Equal(t, []string{"test_database"}, getClosedModuleNames())
+}
+
+func TestCloseContextDeadline(t *testing.T) {
+	resetClosedModuleNames()
+	hanging := &testHangingCloser{release: make(chan struct{})}
+	defer close(hanging.release)
+	wj := New()
+	wj.AddEagerInjector("test_database", injectTestDatabase)
+	wj.AddEagerInjector("test_hanging", func() *testHangingCloser { return hanging })
+	wj.SetActivatingModules([]string{"test_database", "test_hanging"})
+
+	err := wj.DoWire()
+	assert.NoError(t, err, "Failed to DoWire()")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = wj.CloseContext(ctx)
+	var closeErr *CloseError
+	assert.True(t, errors.As(err, &closeErr))
+	// test_hanging is abandoned, test_database and viperjacket are skipped.
+	assert.Len(t, closeErr.Errors, 3)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Contains(t, closeErr.Errors[1].Error(), "module(test_database) is not closed")
+	assert.Empty(t, getClosedModuleNames())
+}
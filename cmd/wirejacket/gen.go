@@ -0,0 +1,273 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/bang9211/wire-jacket/cli"
+)
+
+const genUsage = `usage: wirejacket gen [flags]
+
+gen generates the maps of injectors and the constants of module names
+from the injector functions having the directive in the doc comment.
+
+  //wirejacket:module name=mysql eager
+
+name is the module name in config and eager puts the injector in the
+map of eager injectors.
+
+flags:
+`
+
+const genDirective = "//wirejacket:module"
+
+// genModule is a module of an injector function having the directive.
+type genModule struct {
+	Name     string
+	Const    string
+	Injector string
+	Eager    bool
+}
+
+var genTemplate = template.Must(template.New("gen").Parse(`// Code generated by wirejacket gen. DO NOT EDIT.
+
+package {{.Package}}
+
+// The names of the modules.
+const (
+{{- range .Modules}}
+	{{.Const}} = "{{.Name}}"
+{{- end}}
+)
+
+// {{.Injectors}} stores module_name(key) with injector_func(value) using map.
+var {{.Injectors}} = map[string]interface{}{
+{{- range .Modules}}{{if not .Eager}}
+	{{.Const}}: {{.Injector}},
+{{- end}}{{end}}
+}
+
+// {{.EagerInjectors}} stores module_name(key) with eager injector_func(value) using map.
+var {{.EagerInjectors}} = map[string]interface{}{
+{{- range .Modules}}{{if .Eager}}
+	{{.Const}}: {{.Injector}},
+{{- end}}{{end}}
+}
+`))
+
+func runGen(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("wirejacket gen", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, genUsage)
+		fs.PrintDefaults()
+	}
+	dir := fs.String("dir", ".", "directory of the package having the injector functions")
+	output := fs.String("output", "wirejacket_gen.go", "file to generate in --dir")
+	injectors := fs.String("injectors", "Injectors", "name of the map of injectors")
+	eagerInjectors := fs.String("eager-injectors", "EagerInjectors", "name of the map of eager injectors")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return cli.ExitOK
+		}
+		return cli.ExitUsage
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(stderr, "unexpected arguments %s\n", fs.Args())
+		fs.Usage()
+		return cli.ExitUsage
+	}
+
+	pkgName, modules, err := parseModules(*dir, *output)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return cli.ExitFailure
+	}
+	src, err := generate(pkgName, modules, *injectors, *eagerInjectors)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return cli.ExitFailure
+	}
+	if err := os.WriteFile(filepath.Join(*dir, *output), src, 0o644); err != nil {
+		fmt.Fprintln(stderr, err)
+		return cli.ExitFailure
+	}
+	return cli.ExitOK
+}
+
+// parseModules returns the package name and the modules of the
+// injector functions in the Go files of dir, except the tests and
+// output. The files of all the build tags are parsed, so the injector
+// declared in wire.go and generated in wire_gen.go is one module.
+// The modules are sorted by name.
+func parseModules(dir, output string) (string, []genModule, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", nil, err
+	}
+
+	fset := token.NewFileSet()
+	pkgName := ""
+	modules := map[string]*genModule{}
+	injectorModules := map[string]*genModule{}
+	for _, entry := range entries {
+		fileName := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(fileName, ".go") ||
+			strings.HasSuffix(fileName, "_test.go") || fileName == output {
+			continue
+		}
+		file, err := parser.ParseFile(fset, filepath.Join(dir, fileName), nil, parser.ParseComments)
+		if err != nil {
+			return "", nil, err
+		}
+		if pkgName == "" {
+			pkgName = file.Name.Name
+		} else if pkgName != file.Name.Name {
+			return "", nil, fmt.Errorf("found packages %s and %s in %s",
+				pkgName, file.Name.Name, dir)
+		}
+
+		for _, decl := range file.Decls {
+			fd, ok := decl.(*ast.FuncDecl)
+			if !ok || fd.Doc == nil {
+				continue
+			}
+			for _, comment := range fd.Doc.List {
+				if !strings.HasPrefix(comment.Text, genDirective) {
+					continue
+				}
+				position := fset.Position(comment.Pos())
+				module, err := parseDirective(comment.Text)
+				if err != nil {
+					return "", nil, fmt.Errorf("%s : %w", position, err)
+				}
+				if fd.Recv != nil || fd.Type.TypeParams != nil {
+					return "", nil, fmt.Errorf("%s : injector(%s) should be a function without receiver and type parameters",
+						position, fd.Name.Name)
+				}
+				module.Injector = fd.Name.Name
+
+				if declared := injectorModules[module.Injector]; declared != nil {
+					if *declared != *module {
+						return "", nil, fmt.Errorf("%s : injector(%s) has different directives",
+							position, module.Injector)
+					}
+					continue
+				}
+				if modules[module.Name] != nil {
+					return "", nil, fmt.Errorf("%s : module(%s) is already provided by injector(%s)",
+						position, module.Name, modules[module.Name].Injector)
+				}
+				modules[module.Name] = module
+				injectorModules[module.Injector] = module
+			}
+		}
+	}
+	if pkgName == "" {
+		return "", nil, fmt.Errorf("no Go files in %s", dir)
+	}
+	if len(modules) == 0 {
+		return "", nil, fmt.Errorf("no injector having %s in %s", genDirective, dir)
+	}
+
+	sorted := []genModule{}
+	consts := map[string]string{}
+	for _, module := range modules {
+		if name, ok := consts[module.Const]; ok {
+			return "", nil, fmt.Errorf("module(%s) and module(%s) have the same constant %s",
+				name, module.Name, module.Const)
+		}
+		consts[module.Const] = module.Name
+		sorted = append(sorted, *module)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+	return pkgName, sorted, nil
+}
+
+// parseDirective parses the directive of an injector function.
+//
+//	//wirejacket:module name=mysql eager
+func parseDirective(text string) (*genModule, error) {
+	args := strings.TrimPrefix(text, genDirective)
+	if args != "" && args[0] != ' ' && args[0] != '\t' {
+		return nil, fmt.Errorf("unknown directive %s", strings.Fields(text)[0])
+	}
+
+	module := &genModule{}
+	for _, arg := range strings.Fields(args) {
+		switch {
+		case strings.HasPrefix(arg, "name="):
+			module.Name = strings.TrimPrefix(arg, "name=")
+		case arg == "eager":
+			module.Eager = true
+		default:
+			return nil, fmt.Errorf("unknown option(%s) of %s", arg, genDirective)
+		}
+	}
+	if module.Name == "" {
+		return nil, fmt.Errorf("no name of %s", genDirective)
+	}
+	module.Const = moduleConst(module.Name)
+	if !token.IsIdentifier(module.Const) {
+		return nil, fmt.Errorf("invalid module name(%s), it should have only letters, digits, '_', '-' and '.'",
+			module.Name)
+	}
+	return module, nil
+}
+
+// moduleConst returns the name of the constant of moduleName.
+// mockup_database is ModuleMockupDatabase.
+func moduleConst(moduleName string) string {
+	parts := strings.FieldsFunc(moduleName, func(r rune) bool {
+		return r == '_' || r == '-' || r == '.'
+	})
+	sb := &strings.Builder{}
+	sb.WriteString("Module")
+	for _, part := range parts {
+		first, size := utf8.DecodeRuneInString(part)
+		sb.WriteRune(unicode.ToUpper(first))
+		sb.WriteString(part[size:])
+	}
+	return sb.String()
+}
+
+// generate returns the source of the maps and the constants of modules.
+func generate(pkgName string, modules []genModule, injectors, eagerInjectors string) ([]byte, error) {
+	for _, name := range []string{injectors, eagerInjectors} {
+		if !token.IsIdentifier(name) {
+			return nil, fmt.Errorf("invalid name of map(%s)", name)
+		}
+	}
+	if injectors == eagerInjectors {
+		return nil, fmt.Errorf("the maps of injectors and eager injectors have the same name(%s)", injectors)
+	}
+
+	buf := &bytes.Buffer{}
+	err := genTemplate.Execute(buf, map[string]interface{}{
+		"Package":        pkgName,
+		"Modules":        modules,
+		"Injectors":      injectors,
+		"EagerInjectors": eagerInjectors,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const genTestWire = `//go:build wireinject
+
+package app
+
+//wirejacket:module name=mysql
+func InjectMySQL() (Database, error) {
+	panic("wire")
+}
+
+// InjectServer injects Server.
+//
+//wirejacket:module name=api_server eager
+func InjectServer(db Database) (Server, error) {
+	panic("wire")
+}
+`
+
+const genTestWireGen = `//go:build !wireinject
+
+package app
+
+// InjectMySQL is generated.
+//wirejacket:module name=mysql
+func InjectMySQL() (Database, error) {
+	return nil, nil
+}
+
+func InjectCache() (Cache, error) {
+	return nil, nil
+}
+`
+
+const genTestExpected = `// Code generated by wirejacket gen. DO NOT EDIT.
+
+package app
+
+// The names of the modules.
+const (
+	ModuleApiServer = "api_server"
+	ModuleMysql     = "mysql"
+)
+
+// Injectors stores module_name(key) with injector_func(value) using map.
+var Injectors = map[string]interface{}{
+	ModuleMysql: InjectMySQL,
+}
+
+// EagerInjectors stores module_name(key) with eager injector_func(value) using map.
+var EagerInjectors = map[string]interface{}{
+	ModuleApiServer: InjectServer,
+}
+`
+
+func writeGenTestFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, src := range files {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644)
+		assert.NoError(t, err)
+	}
+	return dir
+}
+
+func TestGen(t *testing.T) {
+	dir := writeGenTestFiles(t, map[string]string{
+		"wire.go":     genTestWire,
+		"wire_gen.go": genTestWireGen,
+		"app_test.go": "package app_test\n",
+	})
+
+	stderr := &bytes.Buffer{}
+	code := run([]string{"gen", "--dir", dir}, &bytes.Buffer{}, stderr)
+	assert.Equal(t, 0, code, stderr.String())
+	generated, err := os.ReadFile(filepath.Join(dir, "wirejacket_gen.go"))
+	assert.NoError(t, err)
+	assert.Equal(t, genTestExpected, string(generated))
+
+	// the generated file is not scanned again.
+	code = run([]string{"gen", "--dir", dir}, &bytes.Buffer{}, stderr)
+	assert.Equal(t, 0, code, stderr.String())
+	regenerated, err := os.ReadFile(filepath.Join(dir, "wirejacket_gen.go"))
+	assert.NoError(t, err)
+	assert.Equal(t, generated, regenerated)
+}
+
+func TestGenMapNames(t *testing.T) {
+	dir := writeGenTestFiles(t, map[string]string{"wire.go": genTestWire})
+
+	stderr := &bytes.Buffer{}
+	code := run([]string{"gen", "--dir", dir, "--output", "modules.go",
+		"--injectors", "lazyInjectors", "--eager-injectors", "eagerInjectors",
+	}, &bytes.Buffer{}, stderr)
+	assert.Equal(t, 0, code, stderr.String())
+	generated, err := os.ReadFile(filepath.Join(dir, "modules.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(generated), "var lazyInjectors = map[string]interface{}{")
+	assert.Contains(t, string(generated), "var eagerInjectors = map[string]interface{}{")
+}
+
+func TestGenErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		src   string
+		cause string
+	}{
+		{"no directive", "package app\n\nfunc InjectMySQL() {}\n", "no injector having"},
+		{"no name", "package app\n\n//wirejacket:module eager\nfunc InjectMySQL() {}\n", "no name of"},
+		{"unknown option", "package app\n\n//wirejacket:module name=mysql lazy\nfunc InjectMySQL() {}\n", "unknown option(lazy)"},
+		{"unknown directive", "package app\n\n//wirejacket:modules name=mysql\nfunc InjectMySQL() {}\n", "unknown directive"},
+		{"invalid name", "package app\n\n//wirejacket:module name=my/sql\nfunc InjectMySQL() {}\n", "invalid module name(my/sql)"},
+		{"method", "package app\n\ntype T struct{}\n\n//wirejacket:module name=mysql\nfunc (T) InjectMySQL() {}\n", "without receiver"},
+		{"duplicate name", "package app\n\n//wirejacket:module name=mysql\nfunc InjectMySQL() {}\n\n" +
+			"//wirejacket:module name=mysql\nfunc InjectMariaDB() {}\n", "already provided by injector(InjectMySQL)"},
+		{"same constant", "package app\n\n//wirejacket:module name=my_sql\nfunc InjectMySQL() {}\n\n" +
+			"//wirejacket:module name=my-sql\nfunc InjectMariaDB() {}\n", "have the same constant ModuleMySql"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := writeGenTestFiles(t, map[string]string{"wire.go": test.src})
+
+			stderr := &bytes.Buffer{}
+			code := run([]string{"gen", "--dir", dir}, &bytes.Buffer{}, stderr)
+			assert.Equal(t, 1, code)
+			assert.Contains(t, stderr.String(), test.cause)
+			_, err := os.Stat(filepath.Join(dir, "wirejacket_gen.go"))
+			assert.True(t, os.IsNotExist(err))
+		})
+	}
+}
+
+func TestGenDifferentDirectives(t *testing.T) {
+	dir := writeGenTestFiles(t, map[string]string{
+		"wire.go":     "package app\n\n//wirejacket:module name=mysql\nfunc InjectMySQL() {}\n",
+		"wire_gen.go": "package app\n\n//wirejacket:module name=mysql eager\nfunc InjectMySQL() {}\n",
+	})
+
+	stderr := &bytes.Buffer{}
+	code := run([]string{"gen", "--dir", dir}, &bytes.Buffer{}, stderr)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr.String(), "injector(InjectMySQL) has different directives")
+}
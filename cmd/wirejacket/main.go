@@ -0,0 +1,87 @@
+// Command wirejacket checks the modules of a config offline.
+//
+//	wirejacket validate --pkg ./wire --config app.conf
+//	wirejacket plan --pkg ./wire --config app.conf --format json
+//	wirejacket graph --pkg ./wire --config app.conf --format mermaid
+//
+// --pkg is the package having the maps of injectors, Injectors and
+// EagerInjectors by default. wirejacket generates a main importing
+// them in a temporary directory of the module of the package, then
+// builds and runs it. See package cli for the commands and flags.
+//
+// gen generates the maps of injectors and the constants of module
+// names from the directives of the injector functions in a package.
+//
+//	//go:generate wirejacket gen
+//
+//	//wirejacket:module name=mysql
+//	func InjectMySQL(config viperjacket.Config) (database.Database, error) { ... }
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/bang9211/wire-jacket/cli"
+)
+
+const genCommand = "  gen       generate the maps of injectors, see 'wirejacket gen -h'"
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("wirejacket", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { cli.WriteUsage(stderr, fs, genCommand) }
+	pkg := fs.String("pkg", ".", "package having the maps of injectors")
+	injectors := fs.String("injectors", "Injectors", "map of injectors in --pkg, none if empty")
+	eagerInjectors := fs.String("eager-injectors", "EagerInjectors", "map of eager injectors in --pkg, none if empty")
+	flags := &cli.Flags{}
+	flags.Register(fs)
+
+	if len(args) == 0 {
+		fs.Usage()
+		return cli.ExitUsage
+	}
+	command := args[0]
+	switch {
+	case cli.IsCommand(command):
+	case command == "gen":
+		return runGen(args[1:], stdout, stderr)
+	case command == "-h", command == "-help", command == "--help", command == "help":
+		fs.Usage()
+		return cli.ExitOK
+	default:
+		fmt.Fprintf(stderr, "unknown command(%s)\n", command)
+		fs.Usage()
+		return cli.ExitUsage
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return cli.ExitOK
+		}
+		return cli.ExitUsage
+	}
+
+	if flags.Config != "" {
+		// the registry runs in the module of --pkg.
+		path, err := filepath.Abs(flags.Config)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return cli.ExitFailure
+		}
+		flags.Config = path
+	}
+
+	return runRegistry(registry{
+		pkg:            *pkg,
+		injectors:      *injectors,
+		eagerInjectors: *eagerInjectors,
+	}, append([]string{command}, flags.Args()...), stdout, stderr)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistrySource(t *testing.T) {
+	src, err := registrySource(registry{
+		injectors:      "Injectors",
+		eagerInjectors: "",
+	}, "example.com/app/wire")
+	assert.NoError(t, err)
+	assert.Contains(t, string(src), `registry "example.com/app/wire"`)
+	assert.Contains(t, string(src), "cli.Main(registry.Injectors, nil)")
+
+	_, err = registrySource(registry{}, "example.com/app/wire")
+	assert.Error(t, err)
+}
+
+func TestRunUsage(t *testing.T) {
+	stderr := &bytes.Buffer{}
+	assert.Equal(t, 2, run(nil, &bytes.Buffer{}, stderr))
+	assert.Contains(t, stderr.String(), "usage:")
+
+	stderr.Reset()
+	assert.Equal(t, 2, run([]string{"deploy"}, &bytes.Buffer{}, stderr))
+	assert.Contains(t, stderr.String(), "unknown command(deploy)")
+
+	assert.Equal(t, 0, run([]string{"help"}, &bytes.Buffer{}, &bytes.Buffer{}))
+	assert.Equal(t, 2, run([]string{"plan", "--unknown"}, &bytes.Buffer{}, &bytes.Buffer{}))
+}
+
+func TestRunMainPackage(t *testing.T) {
+	stderr := &bytes.Buffer{}
+	assert.Equal(t, 1, run([]string{"validate", "--pkg", "."}, &bytes.Buffer{}, stderr))
+	assert.Contains(t, stderr.String(), "is main")
+}
+
+func TestRunRegistry(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the registry of mockup")
+	}
+	config, err := filepath.Abs("../../test.json")
+	assert.NoError(t, err)
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	code := run([]string{"validate",
+		"--pkg", "../../internal/mockup",
+		"--config", config,
+		"--service", "test_example",
+	}, stdout, stderr)
+	assert.Equal(t, 0, code, stderr.String())
+	assert.Equal(t, "ok\n", stdout.String())
+
+	stdout.Reset()
+	stderr.Reset()
+	code = run([]string{"validate",
+		"--pkg", "../../internal/mockup",
+		"--modules", "mockup_blockchain",
+	}, stdout, stderr)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr.String(), "3 problems found")
+
+	matches, err := filepath.Glob(filepath.Join("..", "..", ".wirejacket-*"))
+	assert.NoError(t, err)
+	assert.Empty(t, matches)
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/bang9211/wire-jacket/cli"
+)
+
+// registry is the maps of injectors in pkg.
+type registry struct {
+	pkg            string
+	injectors      string
+	eagerInjectors string
+}
+
+// registryPackage is the package of a registry resolved by go list.
+type registryPackage struct {
+	importPath string
+	moduleDir  string
+}
+
+var registryTemplate = template.Must(template.New("main").Parse(`// Code generated by wirejacket. DO NOT EDIT.
+
+package main
+
+import (
+	"github.com/bang9211/wire-jacket/cli"
+	registry "{{.ImportPath}}"
+)
+
+func main() {
+	cli.Main({{.Injectors}}, {{.EagerInjectors}})
+}
+`))
+
+// registrySource returns the source of the main running package cli
+// with the maps of injectors of r in importPath.
+func registrySource(r registry, importPath string) ([]byte, error) {
+	mapOf := func(name string) string {
+		if name == "" {
+			return "nil"
+		}
+		return "registry." + name
+	}
+	if r.injectors == "" && r.eagerInjectors == "" {
+		return nil, fmt.Errorf("no maps of injectors in package(%s)", importPath)
+	}
+
+	buf := &bytes.Buffer{}
+	err := registryTemplate.Execute(buf, map[string]string{
+		"ImportPath":     importPath,
+		"Injectors":      mapOf(r.injectors),
+		"EagerInjectors": mapOf(r.eagerInjectors),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// resolvePackage finds pkg with go list.
+func resolvePackage(pkg string) (*registryPackage, error) {
+	out, err := exec.Command("go", "list",
+		"-f", "{{.ImportPath}} {{.Name}} {{if .Module}}{{.Module.Dir}}{{end}}",
+		pkg).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("failed to find package(%s) : %s",
+				pkg, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("failed to find package(%s) : %w", pkg, err)
+	}
+
+	fields := strings.SplitN(strings.TrimSpace(string(out)), " ", 3)
+	if len(fields) < 3 || fields[2] == "" {
+		return nil, fmt.Errorf("package(%s) is not in a module", pkg)
+	}
+	if fields[1] == "main" {
+		return nil, fmt.Errorf("package(%s) is main, it can't be imported", pkg)
+	}
+	return &registryPackage{
+		importPath: fields[0],
+		moduleDir:  fields[2],
+	}, nil
+}
+
+// runRegistry builds the main of r in the module of r.pkg and runs it
+// with args. It returns the exit code of the main.
+func runRegistry(r registry, args []string, stdout, stderr io.Writer) int {
+	p, err := resolvePackage(r.pkg)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return cli.ExitFailure
+	}
+	src, err := registrySource(r, p.importPath)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return cli.ExitFailure
+	}
+
+	// the directories starting with '.' are ignored by './...'.
+	dir, err := os.MkdirTemp(p.moduleDir, ".wirejacket-")
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return cli.ExitFailure
+	}
+	defer os.RemoveAll(dir)
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), src, 0o644); err != nil {
+		fmt.Fprintln(stderr, err)
+		return cli.ExitFailure
+	}
+
+	bin := filepath.Join(dir, "registry")
+	build := exec.Command("go", "build", "-o", bin, "./"+filepath.Base(dir))
+	build.Dir = p.moduleDir
+	build.Stdout = stderr
+	build.Stderr = stderr
+	if err := build.Run(); err != nil {
+		fmt.Fprintf(stderr, "failed to build injectors of package(%s) : %s\n", p.importPath, err)
+		return cli.ExitFailure
+	}
+
+	cmd := exec.Command(bin, args...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode()
+		}
+		fmt.Fprintln(stderr, err)
+		return cli.ExitFailure
+	}
+	return cli.ExitOK
+}
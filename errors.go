@@ -0,0 +1,198 @@
+package wirejacket
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var (
+	// ErrNoInjector means there is no injector of a module, or no
+	// activated injector providing the type of a dependency.
+	ErrNoInjector = errors.New("no injector")
+	// ErrModuleNotActivated means a module is not in the activating modules.
+	ErrModuleNotActivated = errors.New("module not activated")
+	// ErrInvalidInjectorSignature means an injector is not a function
+	// returning (module), (module, error), (module, func()) or
+	// (module, func(), error).
+	ErrInvalidInjectorSignature = errors.New("invalid injector signature")
+	// ErrNilModule means an injector returned nil module without error.
+	ErrNilModule = errors.New("nil module")
+	// ErrModuleTypeMismatch means a module doesn't satisfy the requested type.
+	ErrModuleTypeMismatch = errors.New("module type mismatch")
+	// ErrDependencyCycle means the injectors depend on each other.
+	// It is wrapped by CycleError.
+	ErrDependencyCycle = errors.New("dependency cycle")
+	// ErrAmbiguousProvider means more than one activated module provides
+	// the type of a dependency. It is reported by Validate only, wiring
+	// chooses the first in the order of activating modules.
+	ErrAmbiguousProvider = errors.New("ambiguous provider")
+	// ErrModuleNotReady means a module didn't become ready in time.
+	ErrModuleNotReady = errors.New("module not ready")
+	// ErrRestartPolicyExhausted means a Runner failed and its restart
+	// policy allows no more restarts. It is matched by RunError.
+	ErrRestartPolicyExhausted = errors.New("restart policy exhausted")
+)
+
+// InjectionError is returned when a module failed to be injected.
+// Module is the module that failed, DependencyPath is the chain of
+// modules from the requested module to Module, and Cause is the reason.
+// Cause is one of the errors above or the error returned by the injector.
+type InjectionError struct {
+	Module         string
+	DependencyPath []string
+	Cause          error
+}
+
+func (e *InjectionError) Error() string {
+	if len(e.DependencyPath) <= 1 {
+		return fmt.Sprintf("[%s] %s", e.Module, e.Cause)
+	}
+	return fmt.Sprintf("[%s] %s (dependency path : %s)",
+		e.Module, e.Cause, strings.Join(e.DependencyPath, " -> "))
+}
+
+// Unwrap returns the cause of the injection failure.
+func (e *InjectionError) Unwrap() error {
+	return e.Cause
+}
+
+// RollbackError is returned when wiring failed and closing the modules
+// created during the failed attempt failed too.
+// Cause is the original wiring error and CloseErrors are the errors
+// occurred while rolling back.
+type RollbackError struct {
+	Cause       error
+	CloseErrors []error
+}
+
+func (e *RollbackError) Error() string {
+	closeErrors := make([]string, len(e.CloseErrors))
+	for i, err := range e.CloseErrors {
+		closeErrors[i] = err.Error()
+	}
+	return fmt.Sprintf("%s (rollback : %s)",
+		e.Cause, strings.Join(closeErrors, ", "))
+}
+
+// Unwrap returns the original wiring error.
+func (e *RollbackError) Unwrap() error {
+	return e.Cause
+}
+
+// CycleError is returned when the injectors depend on each other.
+// Path is the chain of module names that starts and ends with the same
+// module, and Types[i] is the type that Path[i] needs from Path[i+1].
+type CycleError struct {
+	Path  []string
+	Types []reflect.Type
+}
+
+// newCycleError returns CycleError of the cycle closed by moduleName,
+// from the modules being visited and the types between them.
+func newCycleError(path []string, pathTypes []reflect.Type, moduleName string) *CycleError {
+	start := 0
+	for i, name := range path {
+		if name == moduleName {
+			start = i
+			break
+		}
+	}
+	cyclePath := append([]string{}, path[start:]...)
+	cyclePath = append(cyclePath, moduleName)
+	cycleTypes := append([]reflect.Type{}, pathTypes[start:]...)
+
+	return &CycleError{Path: cyclePath, Types: cycleTypes}
+}
+
+func (e *CycleError) Error() string {
+	edges := make([]string, len(e.Types))
+	for i, dependencyType := range e.Types {
+		edges[i] = fmt.Sprintf("%s needs %s", e.Path[i], dependencyType)
+	}
+	return fmt.Sprintf("%s : %s (%s)", ErrDependencyCycle,
+		strings.Join(e.Path, " -> "), strings.Join(edges, ", "))
+}
+
+// Unwrap returns ErrDependencyCycle.
+func (e *CycleError) Unwrap() error {
+	return ErrDependencyCycle
+}
+
+// CloseError is returned when some modules failed to close.
+// Errors has the error of each module failed, timed out or skipped,
+// in order of closing.
+type CloseError struct {
+	Errors []error
+}
+
+func (e *CloseError) Error() string {
+	closeErrors := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		closeErrors[i] = err.Error()
+	}
+	return fmt.Sprintf("failed to close %d modules : %s",
+		len(e.Errors), strings.Join(closeErrors, ", "))
+}
+
+// Is reports whether any of Errors matches target.
+func (e *CloseError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidationError is returned by Validate. Problems has all the
+// problems found. Each of them is InjectionError of the module having
+// it or CycleError, except no injectors or no activating modules to
+// wire.
+type ValidationError struct {
+	Problems []error
+}
+
+func (e *ValidationError) Error() string {
+	problems := make([]string, len(e.Problems))
+	for i, err := range e.Problems {
+		problems[i] = "- " + err.Error()
+	}
+	return fmt.Sprintf("%d problems found :\n%s",
+		len(e.Problems), strings.Join(problems, "\n"))
+}
+
+// Is reports whether any of Problems matches target.
+func (e *ValidationError) Is(target error) bool {
+	for _, err := range e.Problems {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// RunError is returned when a Runner failed and its restart policy
+// is exhausted. Module is the failed module, Restarts is the number of
+// restarts before the last failure and Cause is the last error of Run.
+type RunError struct {
+	Module   string
+	Restarts int
+	Cause    error
+}
+
+func (e *RunError) Error() string {
+	return fmt.Sprintf("[%s] %s after %d restarts : %s",
+		e.Module, ErrRestartPolicyExhausted, e.Restarts, e.Cause)
+}
+
+// Unwrap returns the last error of Run.
+func (e *RunError) Unwrap() error {
+	return e.Cause
+}
+
+// Is reports whether target is ErrRestartPolicyExhausted.
+func (e *RunError) Is(target error) bool {
+	return target == ErrRestartPolicyExhausted
+}
@@ -0,0 +1,94 @@
+package wirejacket
+
+import (
+	"errors"
+	"testing"
+
+	viperjacket "github.com/bang9211/viper-jacket"
+	"github.com/stretchr/testify/assert"
+)
+
+func injectInvalidSignatureTestServer(config viperjacket.Config) (testServer, error, error) {
+	return &testServerImpl{closeRecorder{"test_server"}}, nil, nil
+}
+
+func TestErrModuleNotActivated(t *testing.T) {
+	wj := New()
+	wj.AddInjector("test_database", injectTestDatabase)
+	wj.AddInjector("test_blockchain", injectTestBlockchain)
+	wj.AddEagerInjector("test_server", injectTestServer)
+	wj.SetActivatingModules([]string{"test_server"})
+
+	err := wj.DoWire()
+	assert.True(t, errors.Is(err, ErrNoInjector))
+
+	wj.SetActivatingModules([]string{"test_blockchain"})
+	err = wj.DoWire()
+	assert.True(t, errors.Is(err, ErrModuleNotActivated))
+	var injectionErr *InjectionError
+	assert.True(t, errors.As(err, &injectionErr))
+	assert.Equal(t, "test_server", injectionErr.Module)
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
+
+func TestErrNoInjector(t *testing.T) {
+	wj := New()
+	wj.AddInjector("test_blockchain", injectTestBlockchain)
+	wj.AddEagerInjector("test_server", injectTestServer)
+	wj.SetActivatingModules([]string{"test_database", "test_blockchain", "test_server"})
+
+	err := wj.DoWire()
+	assert.True(t, errors.Is(err, ErrNoInjector))
+	var injectionErr *InjectionError
+	assert.True(t, errors.As(err, &injectionErr))
+	assert.Equal(t, "test_blockchain", injectionErr.Module)
+	assert.Equal(t, []string{"test_server", "test_blockchain"}, injectionErr.DependencyPath)
+	assert.Contains(t, err.Error(), "wirejacket.testDatabase")
+	assert.Contains(t, err.Error(), "test_server -> test_blockchain")
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
+
+func TestErrInvalidInjectorSignature(t *testing.T) {
+	wj := New()
+	wj.AddEagerInjector("test_server", injectInvalidSignatureTestServer)
+	wj.SetActivatingModules([]string{"test_server"})
+
+	err := wj.DoWire()
+	assert.True(t, errors.Is(err, ErrInvalidInjectorSignature))
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
+
+func TestInjectionErrorCause(t *testing.T) {
+	wj := newTestWireJacket()
+	wj.AddEagerInjector("test_server", injectFailingTestServer)
+
+	err := wj.DoWire()
+	assert.True(t, errors.Is(err, errTestInjection))
+	var injectionErr *InjectionError
+	assert.True(t, errors.As(err, &injectionErr))
+	assert.Equal(t, "test_server", injectionErr.Module)
+	assert.Equal(t, []string{"test_server"}, injectionErr.DependencyPath)
+	assert.Equal(t, "[test_server] test injection error", err.Error())
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
+
+func TestCycleErrorIs(t *testing.T) {
+	wj := New()
+	wj.AddInjector("test_blockchain", injectCyclicTestBlockchain)
+	wj.AddEagerInjector("test_server", injectTestServer)
+	wj.SetActivatingModules([]string{"test_blockchain", "test_server"})
+
+	err := wj.DoWire()
+	assert.True(t, errors.Is(err, ErrDependencyCycle))
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
@@ -0,0 +1,78 @@
+package wirejacket
+
+import "time"
+
+// EventType is the type of Event.
+type EventType string
+
+const (
+	// EventDependencyResolved is emitted for each dependency of Module
+	// before its injector is called. Dependency is the module injected.
+	EventDependencyResolved EventType = "dependency_resolved"
+	// EventInjectorStarted is emitted when the injector of Module is called.
+	EventInjectorStarted EventType = "injector_started"
+	// EventInjectorSucceeded is emitted when Module is injected and ready.
+	// Duration is the time of the injector and the wait for readiness.
+	EventInjectorSucceeded EventType = "injector_succeeded"
+	// EventInjectorFailed is emitted when the injector of Module returned
+	// error, timed out or Module didn't become ready.
+	EventInjectorFailed EventType = "injector_failed"
+	// EventModuleStarted is emitted when Module is started by Start.
+	EventModuleStarted EventType = "module_started"
+	// EventModuleStopped is emitted when Module is stopped by Stop.
+	EventModuleStopped EventType = "module_stopped"
+	// EventModuleStopFailed is emitted when Module failed to stop.
+	EventModuleStopFailed EventType = "module_stop_failed"
+	// EventModuleClosed is emitted when Module is closed.
+	// Duration is the time to close it.
+	EventModuleClosed EventType = "module_closed"
+	// EventModuleCloseFailed is emitted when Module failed to close,
+	// timed out or was skipped by the deadline.
+	EventModuleCloseFailed EventType = "module_close_failed"
+	// EventModuleRunFailed is emitted when Run of a Runner returned error.
+	EventModuleRunFailed EventType = "module_run_failed"
+	// EventModuleRestarting is emitted before a failed Runner restarts.
+	// Restarts is the number of the restart and Delay is the backoff.
+	EventModuleRestarting EventType = "module_restarting"
+	// EventRestartPolicyExhausted is emitted when a failed Runner can't
+	// restart anymore. The app is shut down by Run.
+	EventRestartPolicyExhausted EventType = "restart_policy_exhausted"
+)
+
+// Event is an event of the lifecycle of a module.
+// The fields not related to Type are zero.
+type Event struct {
+	Type       EventType
+	Module     string
+	Time       time.Time
+	Err        error
+	Duration   time.Duration
+	Dependency string
+	Restarts   int
+	Delay      time.Duration
+}
+
+// Listener listens the events of WireJacket for logging, metrics or
+// tracing. It is called synchronously in the goroutine where the event
+// occurred, so it should return quickly and be safe for concurrent use.
+type Listener func(event Event)
+
+// AddListener adds listener to be called on every event.
+func (wj *WireJacket) AddListener(listener Listener) *WireJacket {
+	wj.mu.Lock()
+	defer wj.mu.Unlock()
+	wj.listeners = append(wj.listeners, listener)
+	return wj
+}
+
+// emit calls the listeners with event. wj.mu should not be held.
+func (wj *WireJacket) emit(event Event) {
+	wj.mu.Lock()
+	listeners := wj.listeners
+	wj.mu.Unlock()
+
+	event.Time = time.Now()
+	for _, listener := range listeners {
+		listener(event)
+	}
+}
@@ -0,0 +1,107 @@
+package wirejacket
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// eventRecorder records the events of WireJacket.
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (er *eventRecorder) listen(event Event) {
+	er.mu.Lock()
+	defer er.mu.Unlock()
+	er.events = append(er.events, event)
+}
+
+func (er *eventRecorder) recordedTypes(module string) []EventType {
+	er.mu.Lock()
+	defer er.mu.Unlock()
+	eventTypes := []EventType{}
+	for _, event := range er.events {
+		if event.Module == module {
+			eventTypes = append(eventTypes, event.Type)
+		}
+	}
+	return eventTypes
+}
+
+func (er *eventRecorder) recordedEvents(eventType EventType) []Event {
+	er.mu.Lock()
+	defer er.mu.Unlock()
+	events := []Event{}
+	for _, event := range er.events {
+		if event.Type == eventType {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+func TestEventsOfLifecycle(t *testing.T) {
+	er := &eventRecorder{}
+	lr := &lifecycleRecorder{}
+	wj := newLifecycleTestWireJacket(lr, false)
+	wj.AddListener(er.listen)
+
+	err := wj.DoWire()
+	assert.NoError(t, err, "Failed to DoWire()")
+	err = wj.Start(context.Background())
+	assert.NoError(t, err, "Failed to Start()")
+	err = wj.Stop(context.Background())
+	assert.NoError(t, err, "Failed to Stop()")
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+
+	assert.Equal(t, []EventType{
+		EventDependencyResolved,
+		EventInjectorStarted,
+		EventInjectorSucceeded,
+		EventModuleStarted,
+		EventModuleStopped,
+		EventModuleClosed,
+	}, er.recordedTypes("test_server"))
+	resolved := er.recordedEvents(EventDependencyResolved)
+	assert.Len(t, resolved, 1)
+	assert.Equal(t, "test_database", resolved[0].Dependency)
+	for _, event := range er.recordedEvents(EventInjectorSucceeded) {
+		assert.False(t, event.Time.IsZero())
+		assert.Greater(t, int64(event.Duration), int64(0))
+	}
+	assert.Equal(t, []EventType{EventModuleClosed}, er.recordedTypes(DefaultConfigName))
+}
+
+func TestEventsOfFailures(t *testing.T) {
+	er := &eventRecorder{}
+	wj := newTestWireJacket()
+	wj.AddInjector("test_database", injectFailingCloseTestDatabase)
+	wj.AddEagerInjector("test_server", injectFailingTestServer)
+	wj.AddListener(er.listen)
+
+	err := wj.DoWire()
+	assert.Error(t, err)
+
+	failed := er.recordedEvents(EventInjectorFailed)
+	assert.Len(t, failed, 1)
+	assert.Equal(t, "test_server", failed[0].Module)
+	assert.True(t, errors.Is(failed[0].Err, errTestInjection))
+	closeFailed := er.recordedEvents(EventModuleCloseFailed)
+	assert.Len(t, closeFailed, 1)
+	assert.Equal(t, "test_database", closeFailed[0].Module)
+	assert.Equal(t, []EventType{
+		EventDependencyResolved,
+		EventInjectorStarted,
+		EventInjectorSucceeded,
+		EventModuleClosed,
+	}, er.recordedTypes("test_blockchain"))
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
@@ -1,6 +1,6 @@
 module github.com/bang9211/wire-jacket
 
-go 1.17
+go 1.21
 
 require (
 	github.com/bang9211/viper-jacket v0.0.0-20211116233906-308ef47f0fc9
@@ -0,0 +1,208 @@
+package wirejacket
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"github.com/bang9211/wire-jacket/internal/utils"
+)
+
+// Graph is the dependency graph of the modules resolved by WireJacket.
+type Graph struct {
+	Modules []GraphModule `json:"modules"`
+	Edges   []GraphEdge   `json:"edges"`
+}
+
+// GraphModule is a module in Graph.
+// Injector is the name of the injector function and ReturnType is
+// the type it returns. Implementation is the type of the module bound
+// to ReturnType, only if it is instantiated.
+type GraphModule struct {
+	Name           string   `json:"name"`
+	Injector       string   `json:"injector,omitempty"`
+	ParamTypes     []string `json:"param_types,omitempty"`
+	ReturnType     string   `json:"return_type,omitempty"`
+	Implementation string   `json:"implementation,omitempty"`
+	Eager          bool     `json:"eager"`
+	Activated      bool     `json:"activated"`
+	Instantiated   bool     `json:"instantiated"`
+}
+
+// GraphEdge is a dependency of From on To through a parameter of Type.
+// To is empty if no activated module provides Type.
+type GraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Type string `json:"type"`
+}
+
+// Graph returns the dependency graph of all the injectors and the
+// instantiated modules. The dependency of an instantiated module is
+// the module injected to it, otherwise the provider WireJacket would
+// choose now. No injector is called.
+func (wj *WireJacket) Graph() *Graph {
+	wj.mu.Lock()
+	defer wj.mu.Unlock()
+
+	moduleNames := []string{}
+	for moduleName := range wj.getInjectors() {
+		moduleNames = append(moduleNames, moduleName)
+	}
+	for _, moduleName := range wj.createdModuleNames {
+		if !utils.IsContain(moduleNames, moduleName) {
+			moduleNames = append(moduleNames, moduleName)
+		}
+	}
+	wj.sortByActivatingOrder(moduleNames)
+
+	graph := &Graph{Modules: []GraphModule{}, Edges: []GraphEdge{}}
+	for _, moduleName := range moduleNames {
+		module := GraphModule{
+			Name:         moduleName,
+			Eager:        wj.eagerInjectors[moduleName] != nil,
+			Activated:    utils.IsContain(wj.activatingModuleNames, moduleName),
+			Instantiated: wj.modules[moduleName] != nil,
+		}
+		if module.Instantiated {
+			module.Implementation = fmt.Sprintf("%T", wj.modules[moduleName])
+		}
+
+		injector := wj.getInjector(moduleName)
+		if injector != nil {
+			module.Injector = funcName(injector)
+			injectorFuncType := reflect.TypeOf(injector)
+			if checkInjectorSignature(injectorFuncType) == nil {
+				module.ReturnType = injectorFuncType.Out(0).String()
+				dependencyTypes := wj.getDependencyTypeList(injectorFuncType)
+				for i, dependencyType := range dependencyTypes {
+					module.ParamTypes = append(module.ParamTypes, dependencyType.String())
+					to := ""
+					if recorded, ok := wj.dependencies[moduleName]; ok && i < len(recorded) {
+						to = recorded[i]
+					} else {
+						to = wj.findProvider(dependencyType)
+					}
+					graph.Edges = append(graph.Edges, GraphEdge{
+						From: moduleName,
+						To:   to,
+						Type: dependencyType.String(),
+					})
+				}
+			}
+		}
+		graph.Modules = append(graph.Modules, module)
+	}
+
+	return graph
+}
+
+// funcName returns the name of function f.
+func funcName(f interface{}) string {
+	v := reflect.ValueOf(f)
+	if v.Kind() != reflect.Func {
+		return fmt.Sprintf("%T", f)
+	}
+	if fn := runtime.FuncForPC(v.Pointer()); fn != nil {
+		return fn.Name()
+	}
+	return v.Type().String()
+}
+
+// JSON returns graph in JSON.
+func (g *Graph) JSON() ([]byte, error) {
+	return json.MarshalIndent(g, "", "  ")
+}
+
+// DOT returns graph in Graphviz DOT. The eager modules are bold, the
+// modules not activated are dashed and the instantiated modules are
+// filled. The edge of a type no module provides goes to a red node.
+func (g *Graph) DOT() string {
+	sb := &strings.Builder{}
+	sb.WriteString("digraph wirejacket {\n")
+	sb.WriteString("  rankdir=LR;\n")
+	sb.WriteString("  node [shape=box];\n")
+	for _, module := range g.Modules {
+		styles := []string{}
+		if module.Eager {
+			styles = append(styles, "bold")
+		}
+		if !module.Activated {
+			styles = append(styles, "dashed")
+		}
+		if module.Instantiated {
+			styles = append(styles, "filled")
+		}
+		fmt.Fprintf(sb, "  %q [label=%q", module.Name, strings.Join(module.labelLines(), "\n"))
+		if len(styles) > 0 {
+			fmt.Fprintf(sb, ", style=%q", strings.Join(styles, ","))
+		}
+		sb.WriteString("];\n")
+	}
+	for _, edge := range g.Edges {
+		to := edge.To
+		if to == "" {
+			to = "missing " + edge.Type
+			fmt.Fprintf(sb, "  %q [color=red, fontcolor=red];\n", to)
+		}
+		fmt.Fprintf(sb, "  %q -> %q [label=%q];\n", edge.From, to, edge.Type)
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// Mermaid returns graph in Mermaid flowchart. The eager modules have
+// thick border, the modules not activated have dashed border and the
+// edge of a type no module provides goes to a missing node.
+func (g *Graph) Mermaid() string {
+	ids := map[string]string{}
+	for i, module := range g.Modules {
+		ids[module.Name] = fmt.Sprintf("m%d", i)
+	}
+
+	sb := &strings.Builder{}
+	sb.WriteString("graph LR\n")
+	sb.WriteString("  classDef eager stroke-width:3px\n")
+	sb.WriteString("  classDef inactive stroke-dasharray:5 5\n")
+	sb.WriteString("  classDef missing stroke:#f00,color:#f00\n")
+	for _, module := range g.Modules {
+		id := ids[module.Name]
+		fmt.Fprintf(sb, "  %s[\"%s\"]\n", id,
+			mermaidEscape(strings.Join(module.labelLines(), "<br/>")))
+		if module.Eager {
+			fmt.Fprintf(sb, "  class %s eager\n", id)
+		}
+		if !module.Activated {
+			fmt.Fprintf(sb, "  class %s inactive\n", id)
+		}
+	}
+	for i, edge := range g.Edges {
+		to := ids[edge.To]
+		if to == "" {
+			to = fmt.Sprintf("missing%d", i)
+			fmt.Fprintf(sb, "  %s[\"%s\"]\n", to, mermaidEscape("missing "+edge.Type))
+			fmt.Fprintf(sb, "  class %s missing\n", to)
+		}
+		fmt.Fprintf(sb, "  %s -->|\"%s\"| %s\n", ids[edge.From], mermaidEscape(edge.Type), to)
+	}
+	return sb.String()
+}
+
+// labelLines returns the lines of the label of module.
+func (module GraphModule) labelLines() []string {
+	lines := []string{module.Name}
+	if module.ReturnType != "" {
+		lines = append(lines, module.ReturnType)
+	}
+	if module.Implementation != "" && module.Implementation != module.ReturnType {
+		lines = append(lines, module.Implementation)
+	}
+	return lines
+}
+
+// mermaidEscape escapes the characters breaking the quoted text of Mermaid.
+func mermaidEscape(s string) string {
+	return strings.ReplaceAll(s, "\"", "#quot;")
+}
@@ -0,0 +1,92 @@
+package wirejacket
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGraph(t *testing.T) {
+	wj := newTestWireJacket()
+	wj.AddInjector("test_other_database", injectOtherTestDatabase)
+
+	graph := wj.Graph()
+	names := []string{}
+	for _, module := range graph.Modules {
+		names = append(names, module.Name)
+	}
+	assert.Equal(t, []string{
+		"test_database", "test_blockchain", "test_server",
+		DefaultConfigName, "test_other_database",
+	}, names)
+
+	server := graph.Modules[2]
+	assert.True(t, server.Eager)
+	assert.True(t, server.Activated)
+	assert.False(t, server.Instantiated)
+	assert.Equal(t, "github.com/bang9211/wire-jacket.injectTestServer", server.Injector)
+	assert.Equal(t, "wirejacket.testServer", server.ReturnType)
+	assert.Equal(t, []string{"viperjacket.Config", "wirejacket.testBlockchain"}, server.ParamTypes)
+	assert.False(t, graph.Modules[4].Activated)
+	assert.Contains(t, graph.Edges, GraphEdge{
+		From: "test_server", To: "test_blockchain", Type: "wirejacket.testBlockchain"})
+	assert.Contains(t, graph.Edges, GraphEdge{
+		From: "test_blockchain", To: "test_database", Type: "wirejacket.testDatabase"})
+
+	err := wj.DoWire()
+	assert.NoError(t, err, "Failed to DoWire()")
+	graph = wj.Graph()
+	assert.True(t, graph.Modules[2].Instantiated)
+	assert.Equal(t, "*wirejacket.testServerImpl", graph.Modules[2].Implementation)
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
+
+func TestGraphMissingProvider(t *testing.T) {
+	wj := New()
+	wj.AddEagerInjector("test_blockchain", injectTestBlockchain)
+	wj.SetActivatingModules([]string{"test_blockchain"})
+
+	graph := wj.Graph()
+	assert.Contains(t, graph.Edges, GraphEdge{
+		From: "test_blockchain", To: "", Type: "wirejacket.testDatabase"})
+	assert.Contains(t, graph.DOT(), `"missing wirejacket.testDatabase" [color=red`)
+	assert.Contains(t, graph.Mermaid(), `["missing wirejacket.testDatabase"]`)
+}
+
+func TestGraphJSON(t *testing.T) {
+	graph := newTestWireJacket().Graph()
+
+	data, err := graph.JSON()
+	assert.NoError(t, err)
+	decoded := &Graph{}
+	err = json.Unmarshal(data, decoded)
+	assert.NoError(t, err)
+	assert.Equal(t, graph, decoded)
+}
+
+func TestGraphDOT(t *testing.T) {
+	wj := newTestWireJacket()
+	wj.AddInjector("test_other_database", injectOtherTestDatabase)
+	dot := wj.Graph().DOT()
+
+	assert.True(t, strings.HasPrefix(dot, "digraph wirejacket {\n"))
+	assert.Contains(t, dot, `"test_server" [label="test_server\nwirejacket.testServer", style="bold"];`)
+	assert.Contains(t, dot, `"test_other_database" [label="test_other_database\nwirejacket.testDatabase", style="dashed"];`)
+	assert.Contains(t, dot, `"test_server" -> "test_blockchain" [label="wirejacket.testBlockchain"];`)
+}
+
+func TestGraphMermaid(t *testing.T) {
+	wj := newTestWireJacket()
+	wj.AddInjector("test_other_database", injectOtherTestDatabase)
+	mermaid := wj.Graph().Mermaid()
+
+	assert.True(t, strings.HasPrefix(mermaid, "graph LR\n"))
+	assert.Contains(t, mermaid, `m2["test_server<br/>wirejacket.testServer"]`)
+	assert.Contains(t, mermaid, "class m2 eager")
+	assert.Contains(t, mermaid, "class m4 inactive")
+	assert.Contains(t, mermaid, `m2 -->|"wirejacket.testBlockchain"| m1`)
+}
@@ -0,0 +1,176 @@
+package wirejacket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// HealthChecker is a module reporting its health.
+type HealthChecker interface {
+	// Health returns nil if module is healthy.
+	Health(ctx context.Context) error
+}
+
+// HealthStatus is the status of the health of a module or the app.
+type HealthStatus string
+
+const (
+	// HealthStatusHealthy means the module and its dependencies are healthy.
+	HealthStatusHealthy HealthStatus = "healthy"
+	// HealthStatusDegraded means the module is healthy, but some of
+	// its dependencies are not.
+	HealthStatusDegraded HealthStatus = "degraded"
+	// HealthStatusUnhealthy means Health of the module returned error.
+	HealthStatusUnhealthy HealthStatus = "unhealthy"
+)
+
+// ModuleHealth is the health of a module.
+// Latency is the time Health took, 0 if module is not HealthChecker.
+// UnhealthyDependencies are the dependencies making module degraded.
+type ModuleHealth struct {
+	Module                string
+	Status                HealthStatus
+	Latency               time.Duration
+	Err                   error
+	UnhealthyDependencies []string
+}
+
+// MarshalJSON encodes Latency as string and Err as its message.
+func (mh ModuleHealth) MarshalJSON() ([]byte, error) {
+	errMessage := ""
+	if mh.Err != nil {
+		errMessage = mh.Err.Error()
+	}
+	return json.Marshal(struct {
+		Module                string       `json:"module"`
+		Status                HealthStatus `json:"status"`
+		Latency               string       `json:"latency"`
+		Error                 string       `json:"error,omitempty"`
+		UnhealthyDependencies []string     `json:"unhealthy_dependencies,omitempty"`
+	}{mh.Module, mh.Status, mh.Latency.String(), errMessage, mh.UnhealthyDependencies})
+}
+
+// HealthReport is the health of all the created modules.
+// Status is the worst status of Modules.
+// Modules are in dependency order.
+type HealthReport struct {
+	Status  HealthStatus   `json:"status"`
+	Modules []ModuleHealth `json:"modules"`
+}
+
+// Health checks the health of the created modules concurrently and
+// returns the report. A module not implementing HealthChecker is
+// healthy by itself. A healthy module is degraded if any of its
+// dependencies is unhealthy or degraded.
+func (wj *WireJacket) Health(ctx context.Context) *HealthReport {
+	wj.mu.Lock()
+	order := wj.closeOrder(wj.createdModuleNames)
+	for i, j := 0, len(order)-1; i < j; i, j = i+1, j-1 {
+		order[i], order[j] = order[j], order[i]
+	}
+	modules := map[string]interface{}{}
+	dependencies := map[string][]string{}
+	for _, moduleName := range order {
+		modules[moduleName] = wj.modules[moduleName]
+		dependencies[moduleName] = wj.dependencies[moduleName]
+	}
+	wj.mu.Unlock()
+
+	healths := make([]ModuleHealth, len(order))
+	wg := sync.WaitGroup{}
+	for i, moduleName := range order {
+		healths[i] = ModuleHealth{Module: moduleName, Status: HealthStatusHealthy}
+		checker, ok := modules[moduleName].(HealthChecker)
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func(health *ModuleHealth) {
+			defer wg.Done()
+			start := time.Now()
+			health.Err = checkHealth(ctx, checker)
+			health.Latency = time.Since(start)
+			if health.Err != nil {
+				health.Status = HealthStatusUnhealthy
+			}
+		}(&healths[i])
+	}
+	wg.Wait()
+
+	report := &HealthReport{Status: HealthStatusHealthy, Modules: healths}
+	statuses := map[string]HealthStatus{}
+	for i := range healths {
+		health := &healths[i]
+		if health.Status == HealthStatusHealthy {
+			for _, dependencyName := range dependencies[health.Module] {
+				if statuses[dependencyName] != HealthStatusHealthy {
+					health.Status = HealthStatusDegraded
+					health.UnhealthyDependencies = append(health.UnhealthyDependencies, dependencyName)
+				}
+			}
+		}
+		statuses[health.Module] = health.Status
+		if health.Status == HealthStatusUnhealthy ||
+			(health.Status == HealthStatusDegraded && report.Status == HealthStatusHealthy) {
+			report.Status = health.Status
+		}
+	}
+
+	return report
+}
+
+// checkHealth calls Health of checker, recovering panic as error.
+func checkHealth(ctx context.Context, checker HealthChecker) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("health check panicked : %v", r)
+		}
+	}()
+	return checker.Health(ctx)
+}
+
+// LivenessHandler returns the handler of liveness probe.
+// It responds 200 while WireJacket is not closed, without checking
+// the health of modules, so the failure of a dependency outside the
+// app doesn't restart it. After Close, it responds 503 until a module
+// is created again.
+func (wj *WireJacket) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		wj.mu.Lock()
+		closed := wj.closed
+		wj.mu.Unlock()
+		if closed {
+			http.Error(w, "closed", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	})
+}
+
+// ReadinessHandler returns the handler of readiness probe.
+// It responds HealthReport in JSON with 200 if the app is healthy,
+// 503 otherwise. It responds 503 without the report until DoWire
+// succeeds and after Close. The modules created lazily by GetModule
+// don't make the app ready, call DoWire or Run even without eager
+// injectors.
+func (wj *WireJacket) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		wj.mu.Lock()
+		wired := wj.wired
+		wj.mu.Unlock()
+		if !wired {
+			http.Error(w, "not wired", http.StatusServiceUnavailable)
+			return
+		}
+		report := wj.Health(r.Context())
+		w.Header().Set("Content-Type", "application/json")
+		if report.Status != HealthStatusHealthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(report)
+	})
+}
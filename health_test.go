@@ -0,0 +1,191 @@
+package wirejacket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errTestHealth = errors.New("test health error")
+
+type healthDatabase struct {
+	err error
+}
+
+func (hd *healthDatabase) Health(ctx context.Context) error { return hd.err }
+
+type healthServer struct{}
+
+func (hs *healthServer) Health(ctx context.Context) error { return nil }
+
+func newHealthTestWireJacket(databaseErr error) *WireJacket {
+	wj := New()
+	wj.AddInjector("test_database", func() *healthDatabase {
+		return &healthDatabase{err: databaseErr}
+	})
+	wj.AddEagerInjector("test_server", func(db *healthDatabase) *healthServer {
+		return &healthServer{}
+	})
+	wj.SetActivatingModules([]string{"test_database", "test_server"})
+	return wj
+}
+
+func TestHealth(t *testing.T) {
+	wj := newHealthTestWireJacket(nil)
+	err := wj.DoWire()
+	assert.NoError(t, err, "Failed to DoWire()")
+
+	report := wj.Health(context.Background())
+	assert.Equal(t, HealthStatusHealthy, report.Status)
+	assert.Len(t, report.Modules, 3)
+	for _, health := range report.Modules {
+		assert.Equal(t, HealthStatusHealthy, health.Status, health.Module)
+		assert.NoError(t, health.Err)
+	}
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
+
+func TestHealthDegraded(t *testing.T) {
+	wj := newHealthTestWireJacket(errTestHealth)
+	err := wj.DoWire()
+	assert.NoError(t, err, "Failed to DoWire()")
+
+	report := wj.Health(context.Background())
+	assert.Equal(t, HealthStatusUnhealthy, report.Status)
+	healths := map[string]ModuleHealth{}
+	for _, health := range report.Modules {
+		healths[health.Module] = health
+	}
+	assert.Equal(t, HealthStatusHealthy, healths[DefaultConfigName].Status)
+	assert.Equal(t, HealthStatusUnhealthy, healths["test_database"].Status)
+	assert.True(t, errors.Is(healths["test_database"].Err, errTestHealth))
+	assert.Equal(t, HealthStatusDegraded, healths["test_server"].Status)
+	assert.Equal(t, []string{"test_database"}, healths["test_server"].UnhealthyDependencies)
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
+
+func TestReadinessHandler(t *testing.T) {
+	wj := newHealthTestWireJacket(errTestHealth)
+	err := wj.DoWire()
+	assert.NoError(t, err, "Failed to DoWire()")
+
+	recorder := httptest.NewRecorder()
+	wj.ReadinessHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+
+	report := struct {
+		Status  string
+		Modules []map[string]interface{}
+	}{}
+	err = json.Unmarshal(recorder.Body.Bytes(), &report)
+	assert.NoError(t, err)
+	assert.Equal(t, string(HealthStatusUnhealthy), report.Status)
+	healths := map[string]map[string]interface{}{}
+	for _, health := range report.Modules {
+		healths[health["module"].(string)] = health
+	}
+	assert.Equal(t, string(HealthStatusUnhealthy), healths["test_database"]["status"])
+	assert.Equal(t, errTestHealth.Error(), healths["test_database"]["error"])
+	assert.Equal(t, string(HealthStatusDegraded), healths["test_server"]["status"])
+	assert.Equal(t, []interface{}{"test_database"}, healths["test_server"]["unhealthy_dependencies"])
+	assert.NotEmpty(t, healths["test_server"]["latency"])
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
+
+func TestReadinessHandlerNotWired(t *testing.T) {
+	wj := newHealthTestWireJacket(nil)
+	serveReadiness := func() int {
+		recorder := httptest.NewRecorder()
+		wj.ReadinessHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		return recorder.Code
+	}
+	assert.Equal(t, http.StatusServiceUnavailable, serveReadiness())
+
+	err := wj.DoWire()
+	assert.NoError(t, err, "Failed to DoWire()")
+	assert.Equal(t, http.StatusOK, serveReadiness())
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+	assert.Equal(t, http.StatusServiceUnavailable, serveReadiness())
+
+	err = wj.DoWire()
+	assert.NoError(t, err, "Failed to DoWire()")
+	assert.Equal(t, http.StatusOK, serveReadiness())
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
+
+func TestReadinessHandlerLazyModules(t *testing.T) {
+	wj := New()
+	wj.AddInjector("test_database", func() *healthDatabase {
+		return &healthDatabase{}
+	})
+	wj.SetActivatingModules([]string{"test_database"})
+	serveReadiness := func() int {
+		recorder := httptest.NewRecorder()
+		wj.ReadinessHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		return recorder.Code
+	}
+
+	assert.NotNil(t, wj.GetModule("test_database"))
+	assert.Equal(t, http.StatusServiceUnavailable, serveReadiness())
+
+	// DoWire without eager injectors only makes the app ready.
+	err := wj.DoWire()
+	assert.NoError(t, err, "Failed to DoWire()")
+	assert.Equal(t, http.StatusOK, serveReadiness())
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
+
+func TestReadinessHandlerFailedWiring(t *testing.T) {
+	wj := newHealthTestWireJacket(nil)
+	wj.AddEagerInjector("test_failing", func(db *healthDatabase) (*healthServer, error) {
+		return nil, errTestHealth
+	})
+	wj.SetActivatingModules([]string{"test_database", "test_server", "test_failing"})
+
+	err := wj.DoWire()
+	assert.Error(t, err)
+	recorder := httptest.NewRecorder()
+	wj.ReadinessHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+}
+
+func TestLivenessHandler(t *testing.T) {
+	wj := newHealthTestWireJacket(errTestHealth)
+	err := wj.DoWire()
+	assert.NoError(t, err, "Failed to DoWire()")
+
+	recorder := httptest.NewRecorder()
+	wj.LivenessHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+
+	recorder = httptest.NewRecorder()
+	wj.LivenessHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+
+	err = wj.DoWire()
+	assert.NoError(t, err, "Failed to DoWire()")
+	recorder = httptest.NewRecorder()
+	wj.LivenessHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
@@ -93,7 +93,7 @@ func TestInjectMockupRESTAPIServer(t *testing.T) {
 	NoError(t, mockupRESTAPIServer.Close())
 }
 
-func TestInjectMockupInvalidReturnTest(t *testing.T) {
+func TestInjectMockupRESTAPIServerWithCleanup(t *testing.T) {
 	viperJacket := viperjacket.GetOrCreate()
 	Implements(t, (*viperjacket.Config)(nil), viperJacket, "It must implements of interface viperjacket.Config")
 
@@ -108,11 +108,11 @@ func TestInjectMockupInvalidReturnTest(t *testing.T) {
 	Implements(t, (*Blockchain)(nil), mockbupBlockchain, "It must implements of interface Blockchain")
 	NoError(t, mockbupBlockchain.Init(), "Failed to Init()")
 
-	testImpl, f, err := InjectMockupInvalidReturnTest(viperJacket, mockbupBlockchain)
+	testImpl, f, err := InjectMockupRESTAPIServerWithCleanup(viperJacket, mockbupBlockchain)
 	NotNil(t, testImpl)
 	NotNil(t, f)
 	f()
-	NoError(t, err, "Failed to InjectMockupInvalidImplTest()")
+	NoError(t, err, "Failed to InjectMockupRESTAPIServerWithCleanup()")
 }
 
 func TestInjectMockupInvalidImplTestj(t *testing.T) {
@@ -49,6 +49,14 @@ var EagerInjectors = map[string]interface{}{
 // - func Inject{Implement}() ({Interface}, error) {}
 // - func Inject{Implement}({Interface}) {Interface} {}
 // - func Inject{Implement}({Interface}) ({Interface}, error) {}
+// - func Inject{Implement}(context.Context, {Interface}) ({Interface}, error) {}
+// - func Inject{Implement}({Interface}) ({Interface}, func()) {}
+// - func Inject{Implement}({Interface}) ({Interface}, func(), error) {}
+//
+// context.Context as the first parameter is not a dependency, WireJacket
+// passes the context of DoWireContext or GetModuleContext.
+// The cleanup function(func()) generated by wire is called when the
+// module is closed.
 //
 // Examples :
 //
@@ -88,9 +96,9 @@ func InjectMockupRESTAPIServer(
 	return nil, nil
 }
 
-// InjectMockupInvalidReturnTest injects dependencies and inits of
-// RESTAPIServer and return invalid format.
-func InjectMockupInvalidReturnTest(
+// InjectMockupRESTAPIServerWithCleanup injects dependencies and inits of
+// RESTAPIServer with cleanup function.
+func InjectMockupRESTAPIServerWithCleanup(
 	config viperjacket.Config,
 	blockchain Blockchain,
 ) (RESTAPIServer, func(), error) {
@@ -36,9 +36,9 @@ func InjectMockupRESTAPIServer(config viperjacket.Config, blockchain Blockchain)
 	return restapiServer, nil
 }
 
-// InjectMockupInvalidReturnTest injects dependencies and inits of
-// RESTAPIServer and return invalid format.
-func InjectMockupInvalidReturnTest(config viperjacket.Config, blockchain Blockchain) (RESTAPIServer, func(), error) {
+// InjectMockupRESTAPIServerWithCleanup injects dependencies and inits of
+// RESTAPIServer with cleanup function.
+func InjectMockupRESTAPIServerWithCleanup(config viperjacket.Config, blockchain Blockchain) (RESTAPIServer, func(), error) {
 	restapiServer := NewMockupRESTAPIServer(config, blockchain)
 	return restapiServer, func() {
 	}, nil
@@ -0,0 +1,233 @@
+package wirejacket
+
+import (
+	"context"
+	"fmt"
+	"os/signal"
+	"syscall"
+
+	"github.com/bang9211/wire-jacket/internal/utils"
+)
+
+// ShutdownTimeoutKey is the config key of the deadline for Run to
+// stop and close all the modules, e.g. 'shutdown_timeout=25s'.
+// No deadline by default.
+const ShutdownTimeoutKey = "shutdown_timeout"
+
+// Initializer is a module initialized after wiring.
+type Initializer interface {
+	// Init inits module. It is called once, after the modules it
+	// depends on are initialized.
+	Init() error
+}
+
+// Starter is a module started after wiring.
+type Starter interface {
+	// Start starts module. It is called after the modules it depends
+	// on are started. Start should not block, run the long running
+	// work in a goroutine.
+	Start(ctx context.Context) error
+}
+
+// Stopper is a module stopped before closing. Start, Stop and Init
+// are independent, a Stopper not implementing Starter is stopped too
+// once Start has passed it.
+type Stopper interface {
+	// Stop stops module. It is called before the modules it depends
+	// on are stopped.
+	Stop(ctx context.Context) error
+}
+
+// Start inits and starts the created modules in dependency order.
+// The modules are already ready, see ReadySignaler.
+// Every Initializer is initialized before any Starter is started.
+// A Runner is run in its own goroutine right after it is started,
+// and restarted following its restart policy(see RestartPolicyKeySuffix).
+// The Runners are run with their own context, not ctx. They are
+// cancelled by Stop.
+// A Stopper is marked started without Start, so Stop stops it.
+// The modules already initialized or started are skipped, so the
+// modules created after Start can be started by calling Start again.
+//
+// If a module fails to start, the modules started by this call are
+// stopped in reverse order and the error is returned.
+func (wj *WireJacket) Start(ctx context.Context) error {
+	wj.mu.Lock()
+	startOrder := wj.closeOrder(wj.createdModuleNames)
+	for i, j := 0, len(startOrder)-1; i < j; i, j = i+1, j-1 {
+		startOrder[i], startOrder[j] = startOrder[j], startOrder[i]
+	}
+	modules := map[string]interface{}{}
+	for _, moduleName := range startOrder {
+		modules[moduleName] = wj.modules[moduleName]
+	}
+	initializedModules, startedModules := wj.initializedModules, wj.startedModules
+	wj.mu.Unlock()
+
+	for _, moduleName := range startOrder {
+		initializer, ok := modules[moduleName].(Initializer)
+		if !ok || !wj.markModule(initializedModules, moduleName) {
+			continue
+		}
+		if err := initializer.Init(); err != nil {
+			return fmt.Errorf("failed to init module(%s) : %w", moduleName, err)
+		}
+	}
+
+	startedModuleNames := []string{}
+	for _, moduleName := range startOrder {
+		starter, isStarter := modules[moduleName].(Starter)
+		runner, isRunner := modules[moduleName].(Runner)
+		_, isStopper := modules[moduleName].(Stopper)
+		if (!isStarter && !isRunner && !isStopper) ||
+			!wj.markModule(startedModules, moduleName) {
+			continue
+		}
+		if isStarter {
+			if err := starter.Start(ctx); err != nil {
+				wj.mu.Lock()
+				delete(startedModules, moduleName)
+				wj.mu.Unlock()
+				wj.stopModules(ctx, startedModuleNames)
+				return fmt.Errorf("failed to start module(%s) : %w", moduleName, err)
+			}
+		}
+		startedModuleNames = append(startedModuleNames, moduleName)
+		wj.mu.Lock()
+		wj.startedModuleNames = append(wj.startedModuleNames, moduleName)
+		wj.mu.Unlock()
+		if isRunner {
+			if err := wj.supervise(moduleName, runner); err != nil {
+				wj.stopModules(ctx, startedModuleNames)
+				return err
+			}
+		}
+		wj.emit(Event{Type: EventModuleStarted, Module: moduleName})
+		wj.log().Debug("started module", "module", moduleName,
+			"type", fmt.Sprintf("%T", modules[moduleName]), "runner", isRunner)
+	}
+
+	return nil
+}
+
+// markModule marks moduleName in marks and reports whether it was
+// not marked yet. marks should be guarded by wj.mu.
+func (wj *WireJacket) markModule(marks map[string]bool, moduleName string) bool {
+	wj.mu.Lock()
+	defer wj.mu.Unlock()
+	if marks[moduleName] {
+		return false
+	}
+	marks[moduleName] = true
+	return true
+}
+
+// Stop stops the started modules in reverse order of starting.
+// The Runner of a module is cancelled and waited for before its Stop
+// is called. Modules are not closed, use Close after Stop.
+// All the modules are tried to stop, the errors are logged and the
+// first error is returned.
+func (wj *WireJacket) Stop(ctx context.Context) error {
+	wj.mu.Lock()
+	startedModuleNames := wj.startedModuleNames
+	wj.startedModuleNames = []string{}
+	wj.mu.Unlock()
+
+	return wj.stopModules(ctx, startedModuleNames)
+}
+
+// stopModules stops startedModuleNames in reverse order.
+// It returns the first error of stopping.
+func (wj *WireJacket) stopModules(ctx context.Context, startedModuleNames []string) error {
+	var firstErr error
+	for i := len(startedModuleNames) - 1; i >= 0; i-- {
+		moduleName := startedModuleNames[i]
+		wj.mu.Lock()
+		module := wj.modules[moduleName]
+		delete(wj.startedModules, moduleName)
+		wj.startedModuleNames = utils.RemoveElement(wj.startedModuleNames, moduleName)
+		wj.mu.Unlock()
+
+		errs := []error{}
+		if err := wj.stopSupervision(ctx, moduleName); err != nil {
+			errs = append(errs, err)
+		}
+		if stopper, ok := module.(Stopper); ok {
+			if err := stopper.Stop(ctx); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if len(errs) == 0 {
+			wj.emit(Event{Type: EventModuleStopped, Module: moduleName})
+		}
+		for _, err := range errs {
+			err = fmt.Errorf("failed to stop module(%s) : %w", moduleName, err)
+			wj.emit(Event{Type: EventModuleStopFailed, Module: moduleName, Err: err})
+			wj.log().Error("failed to stop module",
+				"module", moduleName, "type", fmt.Sprintf("%T", module), "error", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	return firstErr
+}
+
+// Run wires, inits and starts the modules, then blocks until SIGINT,
+// SIGTERM, the cancellation of ctx or a Runner exhausted its restart
+// policy. After that, it stops and closes all the modules in reverse
+// order in the deadline of ShutdownTimeoutKey.
+//
+//	func main() {
+//	    wj := wirejacket.New().
+//	        SetInjectors(wire.Injectors).
+//	        SetEagerInjectors(wire.EagerInjectors)
+//	    if err := wj.Run(context.Background()); err != nil {
+//	        log.Fatal(err)
+//	    }
+//	}
+//
+// It returns RunError of the Runner exhausted its restart policy,
+// or the error of wiring, starting, stopping or closing.
+// The cancellation of ctx is not an error.
+func (wj *WireJacket) Run(ctx context.Context) error {
+	signalCtx, stopSignal := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stopSignal()
+	// forget the failure of the Runners started before Run.
+	select {
+	case <-wj.runFailures:
+	default:
+	}
+
+	if err := wj.DoWireContext(signalCtx); err != nil {
+		wj.Close()
+		return err
+	}
+	if err := wj.Start(signalCtx); err != nil {
+		wj.Close()
+		return err
+	}
+
+	var runErr error
+	select {
+	case <-signalCtx.Done():
+	case runErr = <-wj.runFailures:
+	}
+
+	shutdownCtx := context.Background()
+	if timeout := wj.config.GetDuration(ShutdownTimeoutKey, 0); timeout > 0 {
+		var cancel context.CancelFunc
+		shutdownCtx, cancel = context.WithTimeout(shutdownCtx, timeout)
+		defer cancel()
+	}
+	stopErr := wj.Stop(shutdownCtx)
+	closeErr := wj.CloseContext(shutdownCtx)
+	if runErr != nil {
+		return runErr
+	}
+	if stopErr != nil {
+		return stopErr
+	}
+	return closeErr
+}
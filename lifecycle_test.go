@@ -0,0 +1,208 @@
+package wirejacket
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errTestStart = errors.New("test start error")
+
+// lifecycleRecorder records the lifecycle events of the modules.
+type lifecycleRecorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (lr *lifecycleRecorder) record(event string) {
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+	lr.events = append(lr.events, event)
+}
+
+func (lr *lifecycleRecorder) recordedEvents() []string {
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+	return append([]string(nil), lr.events...)
+}
+
+type lifecycleModule struct {
+	name      string
+	recorder  *lifecycleRecorder
+	failStart bool
+}
+
+func (lm *lifecycleModule) Init() error {
+	lm.recorder.record("init:" + lm.name)
+	return nil
+}
+
+func (lm *lifecycleModule) Start(ctx context.Context) error {
+	if lm.failStart {
+		return errTestStart
+	}
+	lm.recorder.record("start:" + lm.name)
+	return nil
+}
+
+func (lm *lifecycleModule) Stop(ctx context.Context) error {
+	lm.recorder.record("stop:" + lm.name)
+	return nil
+}
+
+func (lm *lifecycleModule) Close() error {
+	lm.recorder.record("close:" + lm.name)
+	return nil
+}
+
+type lifecycleDatabase struct{ *lifecycleModule }
+
+type lifecycleServer struct{ *lifecycleModule }
+
+func newLifecycleTestWireJacket(lr *lifecycleRecorder, failServerStart bool) *WireJacket {
+	wj := New()
+	wj.AddInjector("test_database", func() *lifecycleDatabase {
+		return &lifecycleDatabase{&lifecycleModule{name: "test_database", recorder: lr}}
+	})
+	wj.AddEagerInjector("test_server", func(db *lifecycleDatabase) *lifecycleServer {
+		return &lifecycleServer{&lifecycleModule{
+			name: "test_server", recorder: lr, failStart: failServerStart}}
+	})
+	wj.SetActivatingModules([]string{"test_server", "test_database"})
+	return wj
+}
+
+func TestStartStop(t *testing.T) {
+	lr := &lifecycleRecorder{}
+	wj := newLifecycleTestWireJacket(lr, false)
+
+	err := wj.DoWire()
+	assert.NoError(t, err, "Failed to DoWire()")
+
+	err = wj.Start(context.Background())
+	assert.NoError(t, err, "Failed to Start()")
+	// started modules are not started again.
+	err = wj.Start(context.Background())
+	assert.NoError(t, err, "Failed to Start()")
+
+	err = wj.Stop(context.Background())
+	assert.NoError(t, err, "Failed to Stop()")
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+	assert.Equal(t, []string{
+		"init:test_database", "init:test_server",
+		"start:test_database", "start:test_server",
+		"stop:test_server", "stop:test_database",
+		"close:test_server", "close:test_database",
+	}, lr.recordedEvents())
+}
+
+// stopperDatabase only implements Stopper.
+type stopperDatabase struct {
+	recorder *lifecycleRecorder
+}
+
+func (sd *stopperDatabase) Stop(ctx context.Context) error {
+	sd.recorder.record("stop:test_database")
+	return nil
+}
+
+func TestStopWithoutStart(t *testing.T) {
+	lr := &lifecycleRecorder{}
+	wj := New()
+	wj.AddInjector("test_database", func() *stopperDatabase {
+		return &stopperDatabase{recorder: lr}
+	})
+	wj.AddEagerInjector("test_server", func(db *stopperDatabase) *lifecycleServer {
+		return &lifecycleServer{&lifecycleModule{name: "test_server", recorder: lr}}
+	})
+	wj.SetActivatingModules([]string{"test_server", "test_database"})
+
+	err := wj.DoWire()
+	assert.NoError(t, err, "Failed to DoWire()")
+	err = wj.Start(context.Background())
+	assert.NoError(t, err, "Failed to Start()")
+	err = wj.Stop(context.Background())
+	assert.NoError(t, err, "Failed to Stop()")
+	assert.Equal(t, []string{
+		"init:test_server", "start:test_server",
+		"stop:test_server", "stop:test_database",
+	}, lr.recordedEvents())
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
+
+func TestStartFailure(t *testing.T) {
+	lr := &lifecycleRecorder{}
+	wj := newLifecycleTestWireJacket(lr, true)
+
+	err := wj.DoWire()
+	assert.NoError(t, err, "Failed to DoWire()")
+
+	err = wj.Start(context.Background())
+	assert.True(t, errors.Is(err, errTestStart))
+	assert.Contains(t, err.Error(), "test_server")
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+	assert.Equal(t, []string{
+		"init:test_database", "init:test_server",
+		"start:test_database", "stop:test_database",
+		"close:test_server", "close:test_database",
+	}, lr.recordedEvents())
+}
+
+func TestRun(t *testing.T) {
+	lr := &lifecycleRecorder{}
+	wj := newLifecycleTestWireJacket(lr, false)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- wj.Run(ctx)
+	}()
+
+	assert.Eventually(t, func() bool {
+		return len(lr.recordedEvents()) == 4
+	}, time.Second, time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err, "Failed to Run()")
+	case <-time.After(time.Second):
+		t.Fatal("Run() didn't return after cancellation")
+	}
+	assert.Equal(t, []string{
+		"init:test_database", "init:test_server",
+		"start:test_database", "start:test_server",
+		"stop:test_server", "stop:test_database",
+		"close:test_server", "close:test_database",
+	}, lr.recordedEvents())
+}
+
+func TestRunStartFailure(t *testing.T) {
+	lr := &lifecycleRecorder{}
+	wj := newLifecycleTestWireJacket(lr, true)
+
+	err := wj.Run(context.Background())
+	assert.True(t, errors.Is(err, errTestStart))
+	assert.Equal(t, []string{
+		"init:test_database", "init:test_server",
+		"start:test_database", "stop:test_database",
+		"close:test_server", "close:test_database",
+	}, lr.recordedEvents())
+}
+
+func TestRunWiringFailure(t *testing.T) {
+	wj := New()
+
+	err := wj.Run(context.Background())
+	assert.Error(t, err)
+}
@@ -0,0 +1,40 @@
+package wirejacket
+
+import (
+	"log/slog"
+)
+
+// Logger is the logger of WireJacket. *slog.Logger implements it.
+// The wiring decisions and timings are logged at debug level and the
+// failures at error level, with 'module' and 'type' fields.
+type Logger interface {
+	Debug(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// loggerBox boxes Logger to be stored in atomic.Value.
+type loggerBox struct {
+	Logger
+}
+
+// SetLogger sets logger of WireJacket.
+// By default, WireJacket logs with slog.Default().
+func (wj *WireJacket) SetLogger(logger Logger) *WireJacket {
+	wj.logger.Store(loggerBox{logger})
+	return wj
+}
+
+// SetLogHandler sets the logger of WireJacket logging with handler.
+//
+//	wj.SetLogHandler(slog.NewJSONHandler(os.Stderr, nil))
+func (wj *WireJacket) SetLogHandler(handler slog.Handler) *WireJacket {
+	return wj.SetLogger(slog.New(handler))
+}
+
+// log returns the logger of WireJacket.
+func (wj *WireJacket) log() Logger {
+	if box, ok := wj.logger.Load().(loggerBox); ok && box.Logger != nil {
+		return box.Logger
+	}
+	return slog.Default()
+}
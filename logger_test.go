@@ -0,0 +1,87 @@
+package wirejacket
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// readLogRecords returns the records written by slog.JSONHandler.
+func readLogRecords(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	records := []map[string]interface{}{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		record := map[string]interface{}{}
+		assert.NoError(t, json.Unmarshal([]byte(line), &record))
+		records = append(records, record)
+	}
+	return records
+}
+
+// findLogRecord returns the first record of msg having fields.
+func findLogRecord(
+	records []map[string]interface{},
+	msg string,
+	fields map[string]string) map[string]interface{} {
+	for _, record := range records {
+		matched := record["msg"] == msg
+		for key, value := range fields {
+			matched = matched && record[key] == value
+		}
+		if matched {
+			return record
+		}
+	}
+	return nil
+}
+
+func TestSetLogHandler(t *testing.T) {
+	buf := &bytes.Buffer{}
+	wj := newTestWireJacket().SetLogHandler(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	err := wj.DoWire()
+	assert.NoError(t, err, "Failed to DoWire()")
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+
+	records := readLogRecords(t, buf)
+	chosen := findLogRecord(records, "chose injector of dependency",
+		map[string]string{"module": "test_server", "dependency": "test_blockchain"})
+	assert.NotNil(t, chosen)
+	assert.Equal(t, "DEBUG", chosen["level"])
+	assert.Equal(t, "wirejacket.testServer", chosen["type"])
+	assert.Equal(t, "wirejacket.testBlockchain", chosen["dependency_type"])
+
+	injected := findLogRecord(records, "injected module", map[string]string{"module": "test_database"})
+	assert.NotNil(t, injected)
+	assert.Equal(t, "*wirejacket.testDatabaseImpl", injected["type"])
+	assert.Contains(t, injected, "duration")
+	assert.NotNil(t, findLogRecord(records, "closed module", map[string]string{"module": "test_server"}))
+}
+
+func TestSetLogHandlerFailure(t *testing.T) {
+	buf := &bytes.Buffer{}
+	wj := newTestWireJacket().SetLogHandler(slog.NewJSONHandler(buf, nil))
+	wj.AddEagerInjector("test_server", injectFailingTestServer)
+
+	err := wj.DoWire()
+	assert.Error(t, err)
+
+	records := readLogRecords(t, buf)
+	// debug records are not written with the default level.
+	assert.Nil(t, findLogRecord(records, "injected module", map[string]string{"module": "test_database"}))
+	failed := findLogRecord(records, "failed to inject module", map[string]string{"module": "test_server"})
+	assert.NotNil(t, failed)
+	assert.Equal(t, "ERROR", failed["level"])
+	assert.Equal(t, "wirejacket.testServer", failed["type"])
+	assert.Contains(t, failed["error"], errTestInjection.Error())
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
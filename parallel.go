@@ -0,0 +1,110 @@
+package wirejacket
+
+import "context"
+
+// SetParallelWiring sets the number of injectors to call at the same time.
+// By default, WireJacket calls injectors one after another.
+// If concurrency is more than 1, the modules of a wiring plan that don't
+// depend on each other are created in parallel. A module is created only
+// after all of its dependencies are created. If an injector fails, no more
+// injectors are started, the context of the running injectors is canceled
+// and the modules created are rolled back.
+func (wj *WireJacket) SetParallelWiring(concurrency int) *WireJacket {
+	wj.mu.Lock()
+	defer wj.mu.Unlock()
+	wj.concurrency = concurrency
+	return wj
+}
+
+// wiringResult is the result of creating a module of a wiring plan.
+type wiringResult struct {
+	node    *wiringNode
+	created bool
+	err     error
+}
+
+// createModulesParallel creates the modules of plan, running up to
+// concurrency injectors at the same time. It returns the names of
+// the modules created and the first failure if exists.
+// The first failure cancels the context of the injectors running.
+func (wj *WireJacket) createModulesParallel(
+	ctx context.Context,
+	plan []*wiringNode,
+	concurrency int) ([]string, *wiringResult) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	planIndex := map[string]int{}
+	for i, node := range plan {
+		planIndex[node.moduleName] = i
+	}
+	waitings := map[string]int{}
+	dependents := map[string][]*wiringNode{}
+	ready := []*wiringNode{}
+	for _, node := range plan {
+		for _, dependencyName := range node.dependencyNames {
+			if _, ok := planIndex[dependencyName]; ok {
+				waitings[node.moduleName]++
+				dependents[dependencyName] = append(dependents[dependencyName], node)
+			}
+		}
+		if waitings[node.moduleName] == 0 {
+			ready = append(ready, node)
+		}
+	}
+
+	createdModuleNames := []string{}
+	var failure *wiringResult
+	results := make(chan *wiringResult)
+	running := 0
+	for {
+		for failure == nil && running < concurrency && len(ready) > 0 {
+			node := ready[0]
+			ready = ready[1:]
+			running++
+			go func(node *wiringNode) {
+				created, err := wj.createModule(ctx, node)
+				results <- &wiringResult{node: node, created: created, err: err}
+			}(node)
+		}
+		if running == 0 {
+			break
+		}
+
+		result := <-results
+		running--
+		if result.created {
+			createdModuleNames = append(createdModuleNames, result.node.moduleName)
+		}
+		if result.err != nil {
+			if failure == nil {
+				failure = result
+				cancel()
+			}
+			continue
+		}
+		for _, dependent := range dependents[result.node.moduleName] {
+			waitings[dependent.moduleName]--
+			if waitings[dependent.moduleName] == 0 {
+				ready = insertByPlanIndex(ready, dependent, planIndex)
+			}
+		}
+	}
+
+	return createdModuleNames, failure
+}
+
+// insertByPlanIndex inserts node to nodes keeping the order of the plan.
+func insertByPlanIndex(
+	nodes []*wiringNode,
+	node *wiringNode,
+	planIndex map[string]int) []*wiringNode {
+	i := len(nodes)
+	for i > 0 && planIndex[nodes[i-1].moduleName] > planIndex[node.moduleName] {
+		i--
+	}
+	nodes = append(nodes, nil)
+	copy(nodes[i+1:], nodes[i:])
+	nodes[i] = node
+	return nodes
+}
@@ -0,0 +1,140 @@
+package wirejacket
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	viperjacket "github.com/bang9211/viper-jacket"
+	"github.com/stretchr/testify/assert"
+)
+
+type testCache interface {
+	Get() string
+	Close() error
+}
+
+type testCacheImpl struct{ closeRecorder }
+
+func (tc *testCacheImpl) Get() string { return tc.name }
+
+// concurrencyRecorder records the max number of injectors running together
+// and the order of injectors finished.
+type concurrencyRecorder struct {
+	mu       sync.Mutex
+	running  int32
+	max      int32
+	finished []string
+}
+
+func (cr *concurrencyRecorder) run(name string, d time.Duration) {
+	running := atomic.AddInt32(&cr.running, 1)
+	cr.mu.Lock()
+	if running > cr.max {
+		cr.max = running
+	}
+	cr.mu.Unlock()
+	time.Sleep(d)
+	atomic.AddInt32(&cr.running, -1)
+	cr.mu.Lock()
+	cr.finished = append(cr.finished, name)
+	cr.mu.Unlock()
+}
+
+func (cr *concurrencyRecorder) finishedNames() []string {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	return append([]string(nil), cr.finished...)
+}
+
+func newParallelTestWireJacket(cr *concurrencyRecorder, d time.Duration) *WireJacket {
+	wj := New()
+	wj.AddEagerInjector("test_database", func(config viperjacket.Config) (testDatabase, error) {
+		cr.run("test_database", d)
+		return &testDatabaseImpl{closeRecorder{"test_database"}}, nil
+	})
+	wj.AddEagerInjector("test_cache", func(config viperjacket.Config) (testCache, error) {
+		cr.run("test_cache", d)
+		return &testCacheImpl{closeRecorder{"test_cache"}}, nil
+	})
+	wj.AddEagerInjector("test_blockchain", func(db testDatabase) (testBlockchain, error) {
+		cr.run("test_blockchain", d)
+		return &testBlockchainImpl{closeRecorder{"test_blockchain"}}, nil
+	})
+	wj.AddEagerInjector("test_server", func(config viperjacket.Config) (testServer, error) {
+		cr.run("test_server", d)
+		return &testServerImpl{closeRecorder{"test_server"}}, nil
+	})
+	wj.SetActivatingModules([]string{
+		"test_database",
+		"test_cache",
+		"test_blockchain",
+		"test_server",
+	})
+	return wj
+}
+
+func TestDoWireParallel(t *testing.T) {
+	cr := &concurrencyRecorder{}
+	wj := newParallelTestWireJacket(cr, 30*time.Millisecond).SetParallelWiring(3)
+
+	start := time.Now()
+	err := wj.DoWire()
+	elapsed := time.Since(start)
+	assert.NoError(t, err, "Failed to DoWire()")
+	assert.Equal(t, int32(3), cr.max)
+	assert.Less(t, elapsed, 110*time.Millisecond)
+	assert.Equal(t, "test_blockchain", cr.finishedNames()[3], "blockchain should wait for database")
+	assert.Equal(t, []string{"test_database"}, wj.dependencies["test_blockchain"])
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
+
+func TestDoWireParallelLimit(t *testing.T) {
+	cr := &concurrencyRecorder{}
+	wj := newParallelTestWireJacket(cr, 5*time.Millisecond).SetParallelWiring(2)
+
+	err := wj.DoWire()
+	assert.NoError(t, err, "Failed to DoWire()")
+	assert.Equal(t, int32(2), cr.max)
+	assert.Len(t, cr.finishedNames(), 4)
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
+
+func TestDoWireParallelFailure(t *testing.T) {
+	cr := &concurrencyRecorder{}
+	wj := newParallelTestWireJacket(cr, 5*time.Millisecond).SetParallelWiring(2)
+	databaseStarted := make(chan struct{})
+	wj.AddEagerInjector("test_database", func(config viperjacket.Config) (testDatabase, error) {
+		close(databaseStarted)
+		cr.run("test_database", 5*time.Millisecond)
+		return &testDatabaseImpl{closeRecorder{"test_database"}}, nil
+	})
+	wj.AddEagerInjector("test_cache", func(config viperjacket.Config) (testCache, error) {
+		<-databaseStarted
+		cr.run("test_cache", 0)
+		return nil, errTestInjection
+	})
+
+	resetClosedModuleNames()
+	err := wj.DoWire()
+	assert.True(t, errors.Is(err, errTestInjection))
+	var injectionErr *InjectionError
+	assert.True(t, errors.As(err, &injectionErr))
+	assert.Equal(t, "test_cache", injectionErr.Module)
+	assert.NotContains(t, cr.finishedNames(), "test_blockchain", "no more injectors after failure")
+	assert.NotContains(t, cr.finishedNames(), "test_server", "no more injectors after failure")
+	assert.Equal(t, []string{DefaultConfigName}, wj.createdModuleNames)
+	// test_database is abandoned by the cancellation and closed when it returns.
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]string{"test_database"}, getClosedModuleNames())
+	}, time.Second, time.Millisecond)
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
@@ -0,0 +1,141 @@
+package wirejacket
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+// Plan is the modules WireJacket would create, in order of creation.
+type Plan struct {
+	Steps []PlanStep `json:"steps"`
+}
+
+// PlanStep is a module to create in Plan. Injector is the name of the
+// injector function and Type is the type it returns. Lazy is true if
+// DoWire doesn't create the module, it is created on the first
+// GetModule of it or of a module depending on it. Error is the reason
+// a lazy module can't be planned, GetModule of it would fail.
+type PlanStep struct {
+	Module       string           `json:"module"`
+	Injector     string           `json:"injector"`
+	Type         string           `json:"type"`
+	Dependencies []PlanDependency `json:"dependencies,omitempty"`
+	Lazy         bool             `json:"lazy"`
+	Error        string           `json:"error,omitempty"`
+}
+
+// PlanDependency is the module injected to a parameter of Type.
+type PlanDependency struct {
+	Module string `json:"module"`
+	Type   string `json:"type"`
+}
+
+// Plan returns the modules DoWire would create and the order, then
+// the activated modules left to be created lazily. It is a dry run,
+// no injector is called. The modules already created are not included.
+// It returns the error DoWire would return before calling injectors,
+// like no activating modules to wire or CycleError. A lazy module that
+// can't be planned doesn't fail Plan as it doesn't fail DoWire, its
+// step has the Error. Use Validate to check the whole config.
+func (wj *WireJacket) Plan() (*Plan, error) {
+	wj.mu.Lock()
+	defer wj.mu.Unlock()
+
+	if problems := wj.checkWirable(); len(problems) > 0 {
+		return nil, problems[0]
+	}
+	eagerModuleNames := []string{}
+	for moduleName := range wj.eagerInjectors {
+		eagerModuleNames = append(eagerModuleNames, moduleName)
+	}
+	wj.sortByActivatingOrder(eagerModuleNames)
+	eagerPlan, err := wj.buildWiringPlan(eagerModuleNames)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{Steps: []PlanStep{}}
+	planned := map[string]bool{}
+	for _, node := range eagerPlan {
+		planned[node.moduleName] = true
+		plan.Steps = append(plan.Steps, newPlanStep(node, false))
+	}
+	for _, moduleName := range wj.activatingModuleNames {
+		injector := wj.getInjector(moduleName)
+		if injector == nil || planned[moduleName] {
+			continue
+		}
+		lazyPlan, err := wj.buildWiringPlan([]string{moduleName})
+		if err != nil {
+			planned[moduleName] = true
+			plan.Steps = append(plan.Steps, PlanStep{
+				Module:   moduleName,
+				Injector: funcName(injector),
+				Lazy:     true,
+				Error:    err.Error(),
+			})
+			continue
+		}
+		for _, node := range lazyPlan {
+			if !planned[node.moduleName] {
+				planned[node.moduleName] = true
+				plan.Steps = append(plan.Steps, newPlanStep(node, true))
+			}
+		}
+	}
+
+	return plan, nil
+}
+
+// newPlanStep returns PlanStep of node.
+func newPlanStep(node *wiringNode, lazy bool) PlanStep {
+	step := PlanStep{
+		Module:   node.moduleName,
+		Injector: funcName(node.injector),
+		Type:     node.moduleType.String(),
+		Lazy:     lazy,
+	}
+	for i, dependencyName := range node.dependencyNames {
+		step.Dependencies = append(step.Dependencies, PlanDependency{
+			Module: dependencyName,
+			Type:   node.dependencyTypes[i].String(),
+		})
+	}
+	return step
+}
+
+// JSON returns plan in JSON.
+func (p *Plan) JSON() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
+
+// String returns plan as a text table, one module per line. The error
+// of a step that can't be planned follows its dependencies.
+//
+//	MODULE             MODE   TYPE               INJECTOR                                                                DEPENDENCIES
+//	mockup_database    eager  mockup.Database    github.com/bang9211/wire-jacket/internal/mockup.InjectMockupDB          viperjacket
+//	mockup_blockchain  lazy   mockup.Blockchain  github.com/bang9211/wire-jacket/internal/mockup.InjectMockupBlockchain  mockup_database
+func (p *Plan) String() string {
+	sb := &strings.Builder{}
+	tw := tabwriter.NewWriter(sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "MODULE\tMODE\tTYPE\tINJECTOR\tDEPENDENCIES")
+	for _, step := range p.Steps {
+		mode := "eager"
+		if step.Lazy {
+			mode = "lazy"
+		}
+		dependencies := []string{}
+		for _, dependency := range step.Dependencies {
+			dependencies = append(dependencies, dependency.Module)
+		}
+		if step.Error != "" {
+			dependencies = append(dependencies, "error: "+step.Error)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+			step.Module, mode, step.Type, step.Injector, strings.Join(dependencies, " "))
+	}
+	tw.Flush()
+	return sb.String()
+}
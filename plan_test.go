@@ -0,0 +1,161 @@
+package wirejacket
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	viperjacket "github.com/bang9211/viper-jacket"
+	"github.com/stretchr/testify/assert"
+)
+
+func newPlanTestWireJacket() *WireJacket {
+	wj := newTestWireJacket()
+	wj.AddInjector("test_other_server", injectOtherTestServer)
+	wj.SetActivatingModules([]string{
+		"test_database",
+		"test_blockchain",
+		"test_server",
+		"test_other_server",
+	})
+	return wj
+}
+
+func TestPlan(t *testing.T) {
+	wj := newPlanTestWireJacket()
+
+	plan, err := wj.Plan()
+	assert.NoError(t, err, "Failed to Plan()")
+	assert.Empty(t, wj.Spans())
+	assert.Equal(t, []PlanStep{
+		{
+			Module:   "test_database",
+			Injector: "github.com/bang9211/wire-jacket.injectTestDatabase",
+			Type:     "wirejacket.testDatabase",
+			Dependencies: []PlanDependency{
+				{Module: DefaultConfigName, Type: "viperjacket.Config"},
+			},
+		},
+		{
+			Module:   "test_blockchain",
+			Injector: "github.com/bang9211/wire-jacket.injectTestBlockchain",
+			Type:     "wirejacket.testBlockchain",
+			Dependencies: []PlanDependency{
+				{Module: "test_database", Type: "wirejacket.testDatabase"},
+			},
+		},
+		{
+			Module:   "test_server",
+			Injector: "github.com/bang9211/wire-jacket.injectTestServer",
+			Type:     "wirejacket.testServer",
+			Dependencies: []PlanDependency{
+				{Module: DefaultConfigName, Type: "viperjacket.Config"},
+				{Module: "test_blockchain", Type: "wirejacket.testBlockchain"},
+			},
+		},
+		{
+			Module:   "test_other_server",
+			Injector: "github.com/bang9211/wire-jacket.injectOtherTestServer",
+			Type:     "wirejacket.testServer",
+			Dependencies: []PlanDependency{
+				{Module: DefaultConfigName, Type: "viperjacket.Config"},
+				{Module: "test_blockchain", Type: "wirejacket.testBlockchain"},
+			},
+			Lazy: true,
+		},
+	}, plan.Steps)
+
+	err = wj.DoWire()
+	assert.NoError(t, err, "Failed to DoWire()")
+	plan, err = wj.Plan()
+	assert.NoError(t, err, "Failed to Plan()")
+	assert.Len(t, plan.Steps, 1)
+	assert.Equal(t, "test_other_server", plan.Steps[0].Module)
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
+
+func TestPlanDependencyCycle(t *testing.T) {
+	wj := New()
+	wj.AddInjector("test_blockchain", injectCyclicTestBlockchain)
+	wj.AddEagerInjector("test_server", injectTestServer)
+	wj.SetActivatingModules([]string{"test_blockchain", "test_server"})
+
+	plan, err := wj.Plan()
+	assert.Nil(t, plan)
+	assert.True(t, errors.Is(err, ErrDependencyCycle))
+}
+
+func TestPlanUnresolvedLazyModule(t *testing.T) {
+	wj := newPlanTestWireJacket()
+	wj.AddInjector("test_extra", func(config viperjacket.Config, c chan int) (testServer, error) {
+		return injectTestServer(config, nil)
+	})
+	wj.SetActivatingModules([]string{
+		"test_database",
+		"test_blockchain",
+		"test_server",
+		"test_extra",
+		"test_other_server",
+	})
+
+	plan, err := wj.Plan()
+	assert.NoError(t, err, "Failed to Plan()")
+	assert.Len(t, plan.Steps, 5)
+	extra := plan.Steps[3]
+	assert.Equal(t, "test_extra", extra.Module)
+	assert.True(t, extra.Lazy)
+	assert.Contains(t, extra.Error, "chan int")
+	assert.Contains(t, plan.String(), "error: ")
+	assert.Equal(t, "test_other_server", plan.Steps[4].Module)
+	assert.Empty(t, plan.Steps[4].Error)
+
+	err = wj.DoWire()
+	assert.NoError(t, err, "Failed to DoWire()")
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
+
+func TestPlanNothingToWire(t *testing.T) {
+	wj := New()
+	wj.SetActivatingModules([]string{})
+	plan, err := wj.Plan()
+	assert.Nil(t, plan)
+	assert.Equal(t, wj.DoWire(), err)
+
+	wj.AddInjector("test_database", injectTestDatabase)
+	plan, err = wj.Plan()
+	assert.Nil(t, plan)
+	assert.True(t, errors.Is(err, ErrModuleNotActivated))
+	assert.Equal(t, wj.DoWire(), err)
+}
+
+func TestPlanJSON(t *testing.T) {
+	plan, err := newPlanTestWireJacket().Plan()
+	assert.NoError(t, err, "Failed to Plan()")
+
+	data, err := plan.JSON()
+	assert.NoError(t, err)
+	decoded := &Plan{}
+	err = json.Unmarshal(data, decoded)
+	assert.NoError(t, err)
+	assert.Equal(t, plan, decoded)
+}
+
+func TestPlanString(t *testing.T) {
+	plan, err := newPlanTestWireJacket().Plan()
+	assert.NoError(t, err, "Failed to Plan()")
+
+	lines := strings.Split(strings.TrimSpace(plan.String()), "\n")
+	assert.Len(t, lines, 5)
+	assert.Equal(t, []string{"MODULE", "MODE", "TYPE", "INJECTOR", "DEPENDENCIES"},
+		strings.Fields(lines[0]))
+	assert.Equal(t, []string{
+		"test_server", "eager", "wirejacket.testServer",
+		"github.com/bang9211/wire-jacket.injectTestServer",
+		DefaultConfigName, "test_blockchain",
+	}, strings.Fields(lines[3]))
+	assert.Equal(t, "lazy", strings.Fields(lines[4])[1])
+}
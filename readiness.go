@@ -0,0 +1,53 @@
+package wirejacket
+
+import (
+	"context"
+	"time"
+)
+
+// ReadyTimeoutKeySuffix is the suffix of the config key of the
+// timeout for a module to be ready, '{module_name}_ready_timeout'.
+const ReadyTimeoutKeySuffix = "_ready_timeout"
+
+// ReadySignaler is a module becoming ready asynchronously after its
+// injector returned, like a client warming up its connection pool.
+//
+// WireJacket waits for a module to be ready before it is injected to
+// the modules depending on it, so DoWire returns when all the eager
+// modules and their dependencies are ready. If a module isn't ready
+// in its ready timeout or the deadline of ctx, the wiring fails with
+// ErrModuleNotReady and the module is closed.
+type ReadySignaler interface {
+	// Ready returns the channel closed when module is ready.
+	Ready() <-chan struct{}
+}
+
+// ReadyWaiter is ReadySignaler waiting with ctx.
+type ReadyWaiter interface {
+	// WaitReady blocks until module is ready or ctx is done.
+	// It returns nil if module is ready.
+	WaitReady(ctx context.Context) error
+}
+
+// waitReady waits for module to be ready if it is ReadyWaiter or
+// ReadySignaler, until timeout is passed or ctx is done.
+func waitReady(ctx context.Context, module interface{}, timeout time.Duration) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	switch m := module.(type) {
+	case ReadyWaiter:
+		return m.WaitReady(ctx)
+	case ReadySignaler:
+		select {
+		case <-m.Ready():
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
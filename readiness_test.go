@@ -0,0 +1,111 @@
+package wirejacket
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// readyDatabase becomes ready when ready is closed.
+type readyDatabase struct {
+	closeRecorder
+	ready chan struct{}
+}
+
+func (rd *readyDatabase) Ready() <-chan struct{} { return rd.ready }
+
+func (rd *readyDatabase) isReady() bool {
+	select {
+	case <-rd.ready:
+		return true
+	default:
+		return false
+	}
+}
+
+// waitingServer becomes ready after delay.
+type waitingServer struct {
+	delay time.Duration
+}
+
+func (ws *waitingServer) WaitReady(ctx context.Context) error {
+	select {
+	case <-time.After(ws.delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestDoWireWaitsReady(t *testing.T) {
+	db := &readyDatabase{closeRecorder{name: "test_database"}, make(chan struct{})}
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(db.ready)
+	}()
+	readyOnInjection := false
+	wj := New()
+	wj.AddInjector("test_database", func() *readyDatabase { return db })
+	wj.AddEagerInjector("test_server", func(db *readyDatabase) *waitingServer {
+		readyOnInjection = db.isReady()
+		return &waitingServer{delay: 10 * time.Millisecond}
+	})
+	wj.SetActivatingModules([]string{"test_database", "test_server"})
+
+	start := time.Now()
+	err := wj.DoWire()
+	assert.NoError(t, err, "Failed to DoWire()")
+	assert.True(t, readyOnInjection)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
+
+func TestDoWireReadyTimeout(t *testing.T) {
+	t.Setenv("TEST_DATABASE"+strings.ToUpper(ReadyTimeoutKeySuffix), "10ms")
+	resetClosedModuleNames()
+	serverInjected := false
+	wj := New()
+	wj.AddInjector("test_database", func() *readyDatabase {
+		return &readyDatabase{closeRecorder{name: "test_database"}, make(chan struct{})}
+	})
+	wj.AddEagerInjector("test_server", func(db *readyDatabase) *waitingServer {
+		serverInjected = true
+		return &waitingServer{}
+	})
+	wj.SetActivatingModules([]string{"test_database", "test_server"})
+
+	err := wj.DoWire()
+	assert.True(t, errors.Is(err, ErrModuleNotReady))
+	var injectionErr *InjectionError
+	assert.True(t, errors.As(err, &injectionErr))
+	assert.Equal(t, "test_database", injectionErr.Module)
+	assert.False(t, serverInjected)
+	// the module not ready is closed.
+	assert.Equal(t, []string{"test_database"}, getClosedModuleNames())
+	assert.Nil(t, wj.modules["test_database"])
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
+
+func TestDoWireContextReadyDeadline(t *testing.T) {
+	wj := New()
+	wj.AddEagerInjector("test_server", func() *waitingServer {
+		return &waitingServer{delay: time.Second}
+	})
+	wj.SetActivatingModules([]string{"test_server"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := wj.DoWireContext(ctx)
+	assert.True(t, errors.Is(err, ErrModuleNotReady))
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
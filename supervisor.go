@@ -0,0 +1,174 @@
+package wirejacket
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RestartPolicyKeySuffix is the suffix of the config key of the
+// restart policy of a Runner, '{module_name}_restart_policy'.
+// The value is RestartNever or RestartOnFailure.
+const RestartPolicyKeySuffix = "_restart_policy"
+
+// MaxRestartsKeySuffix is the suffix of the config key of the maximum
+// number of restarts of a Runner, '{module_name}_max_restarts'.
+// 0 means no limit.
+const MaxRestartsKeySuffix = "_max_restarts"
+
+// RestartBackoffKeySuffix is the suffix of the config key of the
+// first delay before restarting a Runner, '{module_name}_restart_backoff'.
+// The delay doubles on every restart up to the max restart backoff.
+const RestartBackoffKeySuffix = "_restart_backoff"
+
+// MaxRestartBackoffKeySuffix is the suffix of the config key of the
+// maximum delay before restarting a Runner, '{module_name}_max_restart_backoff'.
+const MaxRestartBackoffKeySuffix = "_max_restart_backoff"
+
+const (
+	// RestartNever doesn't restart a failed Runner. It is the default.
+	RestartNever = "never"
+	// RestartOnFailure restarts a Runner returned error with
+	// exponential backoff.
+	RestartOnFailure = "on-failure"
+)
+
+const (
+	defaultRestartBackoff    = time.Second
+	defaultMaxRestartBackoff = time.Minute
+)
+
+// Runner is a long-running module run in its own goroutine.
+type Runner interface {
+	// Run runs module until ctx is done or it fails.
+	// Run returning nil means the module finished its work.
+	Run(ctx context.Context) error
+}
+
+// restartPolicy is the restart policy of a Runner read from config.
+type restartPolicy struct {
+	policy      string
+	maxRestarts int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+}
+
+// supervision is a Runner being run and watched.
+type supervision struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// readRestartPolicy reads the restart policy of moduleName from config.
+// wj.mu should be held.
+func (wj *WireJacket) readRestartPolicy(moduleName string) (restartPolicy, error) {
+	policy := restartPolicy{
+		policy:      wj.config.GetString(moduleName+RestartPolicyKeySuffix, RestartNever),
+		maxRestarts: wj.config.GetInt(moduleName+MaxRestartsKeySuffix, 0),
+		backoff:     wj.config.GetDuration(moduleName+RestartBackoffKeySuffix, defaultRestartBackoff),
+		maxBackoff:  wj.config.GetDuration(moduleName+MaxRestartBackoffKeySuffix, defaultMaxRestartBackoff),
+	}
+	if policy.policy != RestartNever && policy.policy != RestartOnFailure {
+		return policy, fmt.Errorf("invalid restart policy(%s) of module(%s)",
+			policy.policy, moduleName)
+	}
+	return policy, nil
+}
+
+// supervise runs runner of moduleName in a goroutine and restarts it
+// following its restart policy until it is stopped by stopModules.
+func (wj *WireJacket) supervise(moduleName string, runner Runner) error {
+	wj.mu.Lock()
+	defer wj.mu.Unlock()
+	policy, err := wj.readRestartPolicy(moduleName)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &supervision{cancel: cancel, done: make(chan struct{})}
+	wj.supervisions[moduleName] = s
+	go func() {
+		defer close(s.done)
+		wj.runSupervised(ctx, moduleName, runner, policy)
+	}()
+
+	return nil
+}
+
+// runSupervised runs runner until ctx is done, runner finished or
+// policy is exhausted. When policy is exhausted, the RunError is
+// sent to wj.runFailures to shut down Run.
+func (wj *WireJacket) runSupervised(
+	ctx context.Context,
+	moduleName string,
+	runner Runner,
+	policy restartPolicy) {
+	backoff := policy.backoff
+	for restarts := 0; ; restarts++ {
+		err := runModule(ctx, runner)
+		if err == nil || ctx.Err() != nil {
+			return
+		}
+		wj.emit(Event{Type: EventModuleRunFailed, Module: moduleName, Err: err, Restarts: restarts})
+		wj.log().Error("runner failed", "module", moduleName,
+			"type", fmt.Sprintf("%T", runner), "restarts", restarts, "error", err)
+
+		if policy.policy == RestartNever ||
+			(policy.maxRestarts > 0 && restarts >= policy.maxRestarts) {
+			runErr := &RunError{Module: moduleName, Restarts: restarts, Cause: err}
+			wj.emit(Event{Type: EventRestartPolicyExhausted, Module: moduleName,
+				Err: runErr, Restarts: restarts})
+			select {
+			case wj.runFailures <- runErr:
+			default:
+			}
+			return
+		}
+
+		wj.emit(Event{Type: EventModuleRestarting, Module: moduleName,
+			Err: err, Restarts: restarts + 1, Delay: backoff})
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+		backoff *= 2
+		if backoff > policy.maxBackoff {
+			backoff = policy.maxBackoff
+		}
+	}
+}
+
+// runModule calls Run of runner, recovering panic as error.
+func runModule(ctx context.Context, runner Runner) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("runner panicked : %v", r)
+		}
+	}()
+	return runner.Run(ctx)
+}
+
+// stopSupervision cancels the Runner of moduleName and waits for it
+// to return until ctx is done. It does nothing if moduleName is not
+// supervised.
+func (wj *WireJacket) stopSupervision(ctx context.Context, moduleName string) error {
+	wj.mu.Lock()
+	s := wj.supervisions[moduleName]
+	delete(wj.supervisions, moduleName)
+	wj.mu.Unlock()
+	if s == nil {
+		return nil
+	}
+
+	s.cancel()
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%w : runner didn't return", ctx.Err())
+	}
+}
@@ -0,0 +1,142 @@
+package wirejacket
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errTestRun = errors.New("test run error")
+
+// testRunner fails until it has run failures times, then runs until
+// ctx is done.
+type testRunner struct {
+	failures int32
+	runs     int32
+}
+
+func (tr *testRunner) Run(ctx context.Context) error {
+	if atomic.AddInt32(&tr.runs, 1) <= tr.failures {
+		return errTestRun
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func setRestartPolicy(t *testing.T, moduleName, policy, maxRestarts string) {
+	prefix := strings.ToUpper(moduleName)
+	t.Setenv(prefix+strings.ToUpper(RestartPolicyKeySuffix), policy)
+	t.Setenv(prefix+strings.ToUpper(MaxRestartsKeySuffix), maxRestarts)
+	t.Setenv(prefix+strings.ToUpper(RestartBackoffKeySuffix), "1ms")
+	t.Setenv(prefix+strings.ToUpper(MaxRestartBackoffKeySuffix), "3ms")
+}
+
+func newSupervisorTestWireJacket(runner *testRunner, er *eventRecorder) *WireJacket {
+	wj := New()
+	wj.AddEagerInjector("test_runner", func() *testRunner { return runner })
+	wj.SetActivatingModules([]string{"test_runner"})
+	wj.AddListener(er.listen)
+	return wj
+}
+
+func TestRunRestartPolicyExhausted(t *testing.T) {
+	setRestartPolicy(t, "test_runner", RestartOnFailure, "3")
+	runner := &testRunner{failures: 100}
+	er := &eventRecorder{}
+	wj := newSupervisorTestWireJacket(runner, er)
+
+	err := wj.Run(context.Background())
+	assert.True(t, errors.Is(err, ErrRestartPolicyExhausted))
+	assert.True(t, errors.Is(err, errTestRun))
+	var runErr *RunError
+	assert.True(t, errors.As(err, &runErr))
+	assert.Equal(t, "test_runner", runErr.Module)
+	assert.Equal(t, 3, runErr.Restarts)
+	assert.Equal(t, int32(4), atomic.LoadInt32(&runner.runs))
+
+	assert.Len(t, er.recordedEvents(EventModuleRunFailed), 4)
+	assert.Len(t, er.recordedEvents(EventRestartPolicyExhausted), 1)
+	delays := []time.Duration{}
+	for _, event := range er.recordedEvents(EventModuleRestarting) {
+		delays = append(delays, event.Delay)
+	}
+	assert.Equal(t, []time.Duration{
+		time.Millisecond, 2 * time.Millisecond, 3 * time.Millisecond}, delays)
+}
+
+func TestRunRestartNever(t *testing.T) {
+	setRestartPolicy(t, "test_runner", RestartNever, "0")
+	runner := &testRunner{failures: 1}
+	er := &eventRecorder{}
+	wj := newSupervisorTestWireJacket(runner, er)
+
+	err := wj.Run(context.Background())
+	assert.True(t, errors.Is(err, ErrRestartPolicyExhausted))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&runner.runs))
+	assert.Empty(t, er.recordedEvents(EventModuleRestarting))
+}
+
+func TestRunRestartOnFailure(t *testing.T) {
+	setRestartPolicy(t, "test_runner", RestartOnFailure, "0")
+	runner := &testRunner{failures: 2}
+	er := &eventRecorder{}
+	wj := newSupervisorTestWireJacket(runner, er)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- wj.Run(ctx)
+	}()
+
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&runner.runs) == 3
+	}, time.Second, time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err, "Failed to Run()")
+	case <-time.After(time.Second):
+		t.Fatal("Run() didn't return after cancellation")
+	}
+	assert.Len(t, er.recordedEvents(EventModuleRestarting), 2)
+	assert.Empty(t, er.recordedEvents(EventRestartPolicyExhausted))
+}
+
+func TestStopRunner(t *testing.T) {
+	runner := &testRunner{}
+	wj := newSupervisorTestWireJacket(runner, &eventRecorder{})
+
+	err := wj.DoWire()
+	assert.NoError(t, err, "Failed to DoWire()")
+	err = wj.Start(context.Background())
+	assert.NoError(t, err, "Failed to Start()")
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&runner.runs) == 1
+	}, time.Second, time.Millisecond)
+
+	err = wj.Stop(context.Background())
+	assert.NoError(t, err, "Failed to Stop()")
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
+
+func TestStartInvalidRestartPolicy(t *testing.T) {
+	setRestartPolicy(t, "test_runner", "always", "0")
+	wj := newSupervisorTestWireJacket(&testRunner{}, &eventRecorder{})
+
+	err := wj.DoWire()
+	assert.NoError(t, err, "Failed to DoWire()")
+	err = wj.Start(context.Background())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "always")
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
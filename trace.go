@@ -0,0 +1,293 @@
+package wirejacket
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Span is an injector call recorded while wiring.
+// Parent is the dependent module whose wiring triggered the call, empty
+// if the module was requested directly. Duration includes the wait for
+// readiness. Err is the error of the call if it failed.
+// attempt is the wiring attempt, a DoWire or a GetModule creating
+// modules, the span is recorded in. The parent of a span is in the
+// same attempt.
+type Span struct {
+	Module   string
+	Parent   string
+	Start    time.Time
+	Duration time.Duration
+	Err      error
+	attempt  int
+}
+
+// recordSpan records the injector call of node.
+func (wj *WireJacket) recordSpan(
+	node *wiringNode,
+	start time.Time,
+	duration time.Duration,
+	err error) {
+	parent := ""
+	if len(node.dependencyPath) > 1 {
+		parent = node.dependencyPath[len(node.dependencyPath)-2]
+	}
+
+	wj.mu.Lock()
+	defer wj.mu.Unlock()
+	wj.spans = append(wj.spans, Span{
+		Module:   node.moduleName,
+		Parent:   parent,
+		Start:    start,
+		Duration: duration,
+		Err:      err,
+		attempt:  node.attempt,
+	})
+}
+
+// Spans returns the spans of the injector calls since the last DoWire,
+// including the modules created lazily after it, in order of start.
+func (wj *WireJacket) Spans() []Span {
+	wj.mu.Lock()
+	spans := append([]Span{}, wj.spans...)
+	wj.mu.Unlock()
+
+	sort.SliceStable(spans, func(i, j int) bool {
+		return spans[i].Start.Before(spans[j].Start)
+	})
+	return spans
+}
+
+// traceNode is a span in the tree of the wiring. wireStart is the
+// start of the earliest span of its descendants, so the wiring of a
+// module encloses the wiring of the dependencies it triggered.
+type traceNode struct {
+	span      Span
+	wireStart time.Time
+	parent    *traceNode
+	children  []*traceNode
+	lane      int
+}
+
+func (tn *traceNode) end() time.Time {
+	return tn.span.Start.Add(tn.span.Duration)
+}
+
+// buildTraceTree returns the roots of the tree of spans.
+// A span is the child of the first span of its parent in the same
+// attempt started after it ended. The spans without it are roots.
+func buildTraceTree(spans []Span) []*traceNode {
+	nodes := make([]*traceNode, len(spans))
+	for i, span := range spans {
+		nodes[i] = &traceNode{span: span, wireStart: span.Start}
+	}
+
+	roots := []*traceNode{}
+	for _, node := range nodes {
+		var parent *traceNode
+		for _, candidate := range nodes {
+			if candidate.span.Module == node.span.Parent &&
+				candidate.span.attempt == node.span.attempt &&
+				!candidate.span.Start.Before(node.end()) {
+				parent = candidate
+				break
+			}
+		}
+		if parent == nil {
+			roots = append(roots, node)
+			continue
+		}
+		node.parent = parent
+		parent.children = append(parent.children, node)
+	}
+
+	var setWireStart func(node *traceNode) time.Time
+	setWireStart = func(node *traceNode) time.Time {
+		for _, child := range node.children {
+			if childStart := setWireStart(child); childStart.Before(node.wireStart) {
+				node.wireStart = childStart
+			}
+		}
+		return node.wireStart
+	}
+	for _, root := range roots {
+		setWireStart(root)
+	}
+
+	return roots
+}
+
+// walkTraceTree calls fn with the nodes of roots in depth-first order.
+func walkTraceTree(roots []*traceNode, fn func(node *traceNode, depth int)) {
+	var walk func(nodes []*traceNode, depth int)
+	walk = func(nodes []*traceNode, depth int) {
+		sorted := append([]*traceNode{}, nodes...)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return sorted[i].wireStart.Before(sorted[j].wireStart)
+		})
+		for _, node := range sorted {
+			fn(node, depth)
+			walk(node.children, depth+1)
+		}
+	}
+	walk(roots, 0)
+}
+
+// assignLanes assigns the lane of each node, so a span in a lane is
+// apart from the others, except its ancestors enclosing it. A node is
+// placed in the lane of its parent if possible, the spans wired in
+// parallel are placed in other lanes.
+func assignLanes(roots []*traceNode) {
+	lanes := [][]*traceNode{}
+	isAncestor := func(ancestor, node *traceNode) bool {
+		for p := node.parent; p != nil; p = p.parent {
+			if p == ancestor {
+				return true
+			}
+		}
+		return false
+	}
+	fits := func(lane int, node *traceNode) bool {
+		for _, placed := range lanes[lane] {
+			apart := !placed.end().After(node.wireStart) || !node.end().After(placed.wireStart)
+			if !apart && !isAncestor(placed, node) {
+				return false
+			}
+		}
+		return true
+	}
+
+	var assign func(nodes []*traceNode, parentLane int)
+	assign = func(nodes []*traceNode, parentLane int) {
+		for _, node := range nodes {
+			node.lane = -1
+			if parentLane >= 0 && fits(parentLane, node) {
+				node.lane = parentLane
+			}
+			for lane := 0; node.lane < 0 && lane < len(lanes); lane++ {
+				if fits(lane, node) {
+					node.lane = lane
+				}
+			}
+			if node.lane < 0 {
+				node.lane = len(lanes)
+				lanes = append(lanes, nil)
+			}
+			lanes[node.lane] = append(lanes[node.lane], node)
+			assign(node.children, node.lane)
+		}
+	}
+	assign(roots, -1)
+}
+
+// traceOrigin returns the start of the earliest wiring of roots.
+func traceOrigin(roots []*traceNode) time.Time {
+	var origin time.Time
+	for _, root := range roots {
+		if origin.IsZero() || root.wireStart.Before(origin) {
+			origin = root.wireStart
+		}
+	}
+	return origin
+}
+
+// traceEvent is an event of Chrome trace-event format.
+type traceEvent struct {
+	Name      string                 `json:"name"`
+	Category  string                 `json:"cat,omitempty"`
+	Phase     string                 `json:"ph"`
+	Timestamp int64                  `json:"ts"`
+	Duration  int64                  `json:"dur,omitempty"`
+	PID       int                    `json:"pid"`
+	TID       int                    `json:"tid"`
+	Args      map[string]interface{} `json:"args,omitempty"`
+}
+
+// WriteTrace writes the spans as Chrome trace-event JSON, which can be
+// loaded in chrome://tracing or Perfetto.
+// Each injector call is a span of category 'injector'. The module
+// triggered the wiring of its dependencies has a span of category
+// 'wire' enclosing the spans of the dependencies and its injector.
+func (wj *WireJacket) WriteTrace(w io.Writer) error {
+	roots := buildTraceTree(wj.Spans())
+	assignLanes(roots)
+
+	events := []traceEvent{{
+		Name:  "process_name",
+		Phase: "M",
+		PID:   1,
+		Args:  map[string]interface{}{"name": "wire-jacket"},
+	}}
+	origin := traceOrigin(roots)
+	// every instant is converted to whole microseconds from origin
+	// once, so the spans ending at the same instant end at the same
+	// microsecond and a parent encloses its children exactly.
+	microseconds := func(instant time.Time) int64 {
+		return instant.Sub(origin).Microseconds()
+	}
+	walkTraceTree(roots, func(node *traceNode, depth int) {
+		args := map[string]interface{}{}
+		if node.span.Parent != "" {
+			args["parent"] = node.span.Parent
+		}
+		if node.span.Err != nil {
+			args["error"] = node.span.Err.Error()
+		}
+		if len(node.children) > 0 {
+			events = append(events, traceEvent{
+				Name:      node.span.Module,
+				Category:  "wire",
+				Phase:     "X",
+				Timestamp: microseconds(node.wireStart),
+				Duration:  microseconds(node.end()) - microseconds(node.wireStart),
+				PID:       1,
+				TID:       node.lane + 1,
+				Args:      args,
+			})
+		}
+		events = append(events, traceEvent{
+			Name:      node.span.Module,
+			Category:  "injector",
+			Phase:     "X",
+			Timestamp: microseconds(node.span.Start),
+			Duration:  microseconds(node.end()) - microseconds(node.span.Start),
+			PID:       1,
+			TID:       node.lane + 1,
+			Args:      args,
+		})
+	})
+
+	return json.NewEncoder(w).Encode(struct {
+		TraceEvents     []traceEvent `json:"traceEvents"`
+		DisplayTimeUnit string       `json:"displayTimeUnit"`
+	}{events, "ms"})
+}
+
+// WriteTraceTable writes the spans as a text table. The dependencies
+// are indented under the module triggered them. START is the offset
+// from the start of the first wiring.
+//
+//	MODULE               START  DURATION  ERROR
+//	mockup_blockchain    1ms    200µs
+//	  mockup_database    0s     1ms
+func (wj *WireJacket) WriteTraceTable(w io.Writer) error {
+	roots := buildTraceTree(wj.Spans())
+	origin := traceOrigin(roots)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "MODULE\tSTART\tDURATION\tERROR")
+	walkTraceTree(roots, func(node *traceNode, depth int) {
+		errMessage := ""
+		if node.span.Err != nil {
+			errMessage = node.span.Err.Error()
+		}
+		fmt.Fprintf(tw, "%s%s\t%s\t%s\t%s\n",
+			strings.Repeat("  ", depth), node.span.Module,
+			node.span.Start.Sub(origin), node.span.Duration, errMessage)
+	})
+	return tw.Flush()
+}
@@ -0,0 +1,184 @@
+package wirejacket
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	viperjacket "github.com/bang9211/viper-jacket"
+	"github.com/stretchr/testify/assert"
+)
+
+type chromeTrace struct {
+	TraceEvents []struct {
+		Name     string `json:"name"`
+		Category string `json:"cat"`
+		Phase    string `json:"ph"`
+		TS       int64  `json:"ts"`
+		Dur      int64  `json:"dur"`
+		TID      int    `json:"tid"`
+	} `json:"traceEvents"`
+}
+
+func TestSpans(t *testing.T) {
+	wj := newTestWireJacket()
+	err := wj.DoWire()
+	assert.NoError(t, err, "Failed to DoWire()")
+
+	spans := wj.Spans()
+	assert.Len(t, spans, 3)
+	parents := map[string]string{}
+	for _, span := range spans {
+		parents[span.Module] = span.Parent
+		assert.NoError(t, span.Err)
+	}
+	assert.Equal(t, map[string]string{
+		"test_database":   "test_blockchain",
+		"test_blockchain": "test_server",
+		"test_server":     "",
+	}, parents)
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
+
+func TestWriteTrace(t *testing.T) {
+	wj := newTestWireJacket()
+	err := wj.DoWire()
+	assert.NoError(t, err, "Failed to DoWire()")
+
+	buf := &bytes.Buffer{}
+	err = wj.WriteTrace(buf)
+	assert.NoError(t, err)
+	trace := chromeTrace{}
+	err = json.Unmarshal(buf.Bytes(), &trace)
+	assert.NoError(t, err)
+
+	wires := map[string][2]int64{}
+	injectors := map[string][2]int64{}
+	for _, event := range trace.TraceEvents {
+		if event.Phase != "X" {
+			continue
+		}
+		// sequential wiring is in a lane.
+		assert.Equal(t, 1, event.TID)
+		interval := [2]int64{event.TS, event.TS + event.Dur}
+		if event.Category == "wire" {
+			wires[event.Name] = interval
+		} else {
+			injectors[event.Name] = interval
+		}
+	}
+	assert.Len(t, injectors, 3)
+	assert.Len(t, wires, 2)
+	// the wiring of a module encloses the injectors of its dependencies.
+	assert.LessOrEqual(t, wires["test_server"][0], injectors["test_database"][0])
+	assert.GreaterOrEqual(t, wires["test_server"][1], injectors["test_server"][1])
+	assert.LessOrEqual(t, wires["test_server"][0], wires["test_blockchain"][0])
+	assert.GreaterOrEqual(t, wires["test_blockchain"][1], injectors["test_database"][1])
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
+
+func TestWriteTraceParallel(t *testing.T) {
+	wj := New()
+	wj.AddInjector("test_database", func(config viperjacket.Config) (testDatabase, error) {
+		time.Sleep(20 * time.Millisecond)
+		return injectTestDatabase(config)
+	})
+	wj.AddInjector("test_cache", func() testCache {
+		time.Sleep(20 * time.Millisecond)
+		return &testCacheImpl{closeRecorder{name: "test_cache"}}
+	})
+	wj.AddEagerInjector("test_blockchain", func(db testDatabase, cache testCache) testBlockchain {
+		return &testBlockchainImpl{closeRecorder{name: "test_blockchain"}}
+	})
+	wj.SetActivatingModules([]string{"test_database", "test_cache", "test_blockchain"})
+	wj.SetParallelWiring(2)
+
+	err := wj.DoWire()
+	assert.NoError(t, err, "Failed to DoWire()")
+
+	buf := &bytes.Buffer{}
+	err = wj.WriteTrace(buf)
+	assert.NoError(t, err)
+	trace := chromeTrace{}
+	err = json.Unmarshal(buf.Bytes(), &trace)
+	assert.NoError(t, err)
+	lanes := map[string]int{}
+	for _, event := range trace.TraceEvents {
+		if event.Category == "injector" {
+			lanes[event.Name] = event.TID
+		}
+	}
+	// the dependencies wired in parallel are in different lanes.
+	assert.NotEqual(t, lanes["test_database"], lanes["test_cache"])
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
+
+func TestWriteTraceTable(t *testing.T) {
+	wj := newTestWireJacket()
+	wj.AddEagerInjector("test_server", injectFailingTestServer)
+	err := wj.DoWire()
+	assert.Error(t, err)
+
+	buf := &bytes.Buffer{}
+	err = wj.WriteTraceTable(buf)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 4)
+	assert.True(t, strings.HasPrefix(lines[0], "MODULE"))
+	assert.True(t, strings.HasPrefix(lines[1], "test_server "))
+	assert.Contains(t, lines[1], errTestInjection.Error())
+	assert.True(t, strings.HasPrefix(lines[2], "  test_blockchain "))
+	assert.True(t, strings.HasPrefix(lines[3], "    test_database "))
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
+
+func TestSpansOfAttempts(t *testing.T) {
+	wj := newTestWireJacket()
+	failures := 1
+	wj.AddInjector("test_blockchain", func(db testDatabase) (testBlockchain, error) {
+		if failures > 0 {
+			failures--
+			return nil, errTestInjection
+		}
+		return injectTestBlockchain(db)
+	})
+
+	// the failed attempt has no span of test_server.
+	_, err := wj.GetModuleE("test_server")
+	assert.Error(t, err)
+	_, err = wj.GetModuleE("test_server")
+	assert.NoError(t, err)
+	assert.Len(t, wj.Spans(), 5)
+	buf := &bytes.Buffer{}
+	err = wj.WriteTraceTable(buf)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 6)
+	assert.True(t, strings.HasPrefix(lines[1], "test_blockchain "))
+	assert.Contains(t, lines[1], errTestInjection.Error())
+	assert.True(t, strings.HasPrefix(lines[2], "  test_database "))
+	assert.True(t, strings.HasPrefix(lines[3], "test_server "))
+
+	// DoWire starts the trace again.
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+	err = wj.DoWire()
+	assert.NoError(t, err, "Failed to DoWire()")
+	assert.Len(t, wj.Spans(), 3)
+	for _, span := range wj.Spans() {
+		assert.NoError(t, span.Err)
+	}
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
@@ -0,0 +1,79 @@
+package wirejacket
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Get returns the module of type T, like GetModuleByTypeE.
+// T is usually the interface of the module.
+//
+// Example :
+//
+// blockchain, err := wirejacket.Get[mockup.Blockchain](wj)
+//
+// If no exists, it tries to create module using injector and returns.
+func Get[T any](wj *WireJacket) (T, error) {
+	var zero T
+	module, err := wj.GetModuleByTypeE((*T)(nil))
+	if err != nil {
+		return zero, err
+	}
+
+	return castModule[T]("", module)
+}
+
+// GetNamed returns the module of moduleName as type T, like GetModuleE.
+// It returns ErrModuleTypeMismatch if the activated implementation of
+// moduleName doesn't satisfy T. The type the injector returns is
+// checked before calling it, so a mismatched T creates no module.
+//
+// Example :
+//
+// blockchain, err := wirejacket.GetNamed[mockup.Blockchain](wj, "mockup_blockchain")
+func GetNamed[T any](wj *WireJacket, moduleName string) (T, error) {
+	var zero T
+	if err := wj.checkModuleType(moduleName, reflect.TypeOf((*T)(nil)).Elem()); err != nil {
+		return zero, err
+	}
+	module, err := wj.GetModuleE(moduleName)
+	if err != nil {
+		return zero, err
+	}
+
+	return castModule[T](moduleName, module)
+}
+
+// checkModuleType returns ErrModuleTypeMismatch if the module of
+// moduleName is not created and the type its injector returns is not
+// assignable to moduleType. The other problems of the injector are
+// left to GetModuleE.
+func (wj *WireJacket) checkModuleType(moduleName string, moduleType reflect.Type) error {
+	wj.mu.Lock()
+	defer wj.mu.Unlock()
+	if wj.modules[moduleName] != nil {
+		return nil
+	}
+	injector := wj.getInjector(moduleName)
+	if injector == nil {
+		return nil
+	}
+	injectorFuncType := reflect.TypeOf(injector)
+	if checkInjectorSignature(injectorFuncType) != nil {
+		return nil
+	}
+	if !injectorFuncType.Out(0).AssignableTo(moduleType) {
+		return fmt.Errorf("%w : module(%s) of type(%s) doesn't satisfy %s",
+			ErrModuleTypeMismatch, moduleName, injectorFuncType.Out(0), moduleType)
+	}
+	return nil
+}
+
+func castModule[T any](moduleName string, module interface{}) (T, error) {
+	typed, ok := module.(T)
+	if !ok {
+		return typed, fmt.Errorf("%w : module(%s) of type(%T) doesn't satisfy %s",
+			ErrModuleTypeMismatch, moduleName, module, reflect.TypeOf((*T)(nil)).Elem())
+	}
+	return typed, nil
+}
@@ -0,0 +1,59 @@
+package wirejacket
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	viperjacket "github.com/bang9211/viper-jacket"
+	"github.com/bang9211/wire-jacket/internal/mockup"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGet(t *testing.T) {
+	wj := New().
+		SetInjectors(mockup.Injectors).
+		SetEagerInjectors(mockup.EagerInjectors)
+
+	config, err := Get[viperjacket.Config](wj)
+	assert.NoError(t, err)
+	assert.NotNil(t, config)
+	blockchain, err := Get[mockup.Blockchain](wj)
+	assert.NoError(t, err)
+	assert.NoError(t, blockchain.Init())
+	assert.Equal(t, blockchain, wj.GetModule("mockup_blockchain"))
+
+	writer, err := Get[io.Writer](wj)
+	assert.True(t, errors.Is(err, ErrNoInjector))
+	assert.Nil(t, writer)
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
+
+func TestGetNamed(t *testing.T) {
+	wj := New().
+		SetInjectors(mockup.Injectors).
+		SetEagerInjectors(mockup.EagerInjectors)
+
+	// the mismatched type creates no module.
+	server, err := GetNamed[mockup.ExplorerServer](wj, "mockup_blockchain")
+	assert.True(t, errors.Is(err, ErrModuleTypeMismatch))
+	assert.Nil(t, server)
+	assert.NotContains(t, wj.modules, "mockup_blockchain")
+	assert.NotContains(t, wj.modules, "mockup_database")
+
+	blockchain, err := GetNamed[mockup.Blockchain](wj, "mockup_blockchain")
+	assert.NoError(t, err)
+	assert.NotNil(t, blockchain)
+
+	server, err = GetNamed[mockup.ExplorerServer](wj, "mockup_blockchain")
+	assert.True(t, errors.Is(err, ErrModuleTypeMismatch))
+	assert.Nil(t, server)
+
+	_, err = GetNamed[mockup.Blockchain](wj, "no_exists")
+	assert.True(t, errors.Is(err, ErrNoInjector))
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
@@ -0,0 +1,171 @@
+package wirejacket
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/bang9211/wire-jacket/internal/utils"
+)
+
+// Validate checks the activating modules using only the signatures of
+// the injectors. No injector is called. It checks that
+//
+//   - every activating module and eager injector has an activated injector.
+//   - every injector returns (module), (module, error), (module, func())
+//     or (module, func(), error).
+//   - every parameter of an injector has exactly one activated provider.
+//   - the injectors don't depend on each other.
+//
+// It also reports no injectors or no activating modules, which DoWire
+// refuses to wire.
+// It returns ValidationError having all the problems found, or nil.
+func (wj *WireJacket) Validate() error {
+	wj.mu.Lock()
+	defer wj.mu.Unlock()
+
+	problems := wj.checkWirable()
+	addProblem := func(moduleName string, cause error) {
+		problems = append(problems, &InjectionError{
+			Module:         moduleName,
+			DependencyPath: []string{moduleName},
+			Cause:          cause,
+		})
+	}
+
+	moduleNames := append([]string{}, wj.activatingModuleNames...)
+	for moduleName := range wj.eagerInjectors {
+		if !utils.IsContain(moduleNames, moduleName) {
+			moduleNames = append(moduleNames, moduleName)
+		}
+	}
+	wj.sortByActivatingOrder(moduleNames)
+
+	dependencies := map[string][]string{}
+	dependencyTypes := map[string][]reflect.Type{}
+	checked := map[string]bool{}
+	for _, moduleName := range moduleNames {
+		if checked[moduleName] {
+			continue
+		}
+		checked[moduleName] = true
+		if !utils.IsContain(wj.activatingModuleNames, moduleName) {
+			addProblem(moduleName, fmt.Errorf("%w : eager injector of module(%s) is not activated",
+				ErrModuleNotActivated, moduleName))
+			continue
+		}
+		injector := wj.getInjector(moduleName)
+		if injector == nil {
+			if wj.modules[moduleName] == nil {
+				addProblem(moduleName, fmt.Errorf("%w : no injector of module(%s)",
+					ErrNoInjector, moduleName))
+			}
+			continue
+		}
+		injectorFuncType := reflect.TypeOf(injector)
+		if err := checkInjectorSignature(injectorFuncType); err != nil {
+			addProblem(moduleName, err)
+			continue
+		}
+
+		for _, dependencyType := range wj.getDependencyTypeList(injectorFuncType) {
+			providers := wj.findProviders(dependencyType)
+			switch len(providers) {
+			case 0:
+				addProblem(moduleName, fmt.Errorf("%w : no activated injector of dependency(%s)",
+					ErrNoInjector, dependencyType))
+				continue
+			case 1:
+			default:
+				addProblem(moduleName, fmt.Errorf("%w : dependency(%s) is provided by %s",
+					ErrAmbiguousProvider, dependencyType, strings.Join(providers, ", ")))
+			}
+			dependencies[moduleName] = append(dependencies[moduleName], wj.findProvider(dependencyType))
+			dependencyTypes[moduleName] = append(dependencyTypes[moduleName], dependencyType)
+		}
+	}
+
+	for _, cycleErr := range findCycles(moduleNames, dependencies, dependencyTypes) {
+		problems = append(problems, cycleErr)
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// findProviders returns the names of all the activated modules
+// providing dependencyType, in the order of activating modules.
+// wj.mu should be held.
+func (wj *WireJacket) findProviders(dependencyType reflect.Type) []string {
+	providers := []string{}
+	for _, moduleName := range wj.activatingModuleNames {
+		if utils.IsContain(providers, moduleName) {
+			continue
+		}
+		if module := wj.modules[moduleName]; module != nil {
+			if reflect.ValueOf(module).CanConvert(dependencyType) {
+				providers = append(providers, moduleName)
+			}
+			continue
+		}
+		injector := wj.getInjector(moduleName)
+		if injector == nil {
+			continue
+		}
+		injectorFuncType := reflect.TypeOf(injector)
+		if injectorFuncType.Kind() == reflect.Func &&
+			injectorFuncType.NumOut() > 0 &&
+			injectorFuncType.Out(0) == dependencyType {
+			providers = append(providers, moduleName)
+		}
+	}
+	return providers
+}
+
+// findCycles returns CycleError of every cycle of dependencies found
+// from moduleNames. A cycle is reported once, whichever module it
+// is found from.
+func findCycles(
+	moduleNames []string,
+	dependencies map[string][]string,
+	dependencyTypes map[string][]reflect.Type) []*CycleError {
+	cycles := []*CycleError{}
+	found := map[string]bool{}
+	visiting := map[string]bool{}
+	visited := map[string]bool{}
+	path := []string{}
+	pathTypes := []reflect.Type{}
+	var visit func(moduleName string)
+	visit = func(moduleName string) {
+		if visiting[moduleName] {
+			cycleErr := newCycleError(path, pathTypes, moduleName)
+			members := append([]string{}, cycleErr.Path[1:]...)
+			sort.Strings(members)
+			if key := strings.Join(members, " "); !found[key] {
+				found[key] = true
+				cycles = append(cycles, cycleErr)
+			}
+			return
+		}
+		if visited[moduleName] {
+			return
+		}
+		visiting[moduleName] = true
+		path = append(path, moduleName)
+		for i, dependencyName := range dependencies[moduleName] {
+			pathTypes = append(pathTypes, dependencyTypes[moduleName][i])
+			visit(dependencyName)
+			pathTypes = pathTypes[:len(pathTypes)-1]
+		}
+		path = path[:len(path)-1]
+		visiting[moduleName] = false
+		visited[moduleName] = true
+	}
+	for _, moduleName := range moduleNames {
+		visit(moduleName)
+	}
+	return cycles
+}
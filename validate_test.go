@@ -0,0 +1,119 @@
+package wirejacket
+
+import (
+	"errors"
+	"testing"
+
+	viperjacket "github.com/bang9211/viper-jacket"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	called := false
+	wj := New()
+	wj.AddInjector("test_database", func(config viperjacket.Config) (testDatabase, error) {
+		called = true
+		return injectTestDatabase(config)
+	})
+	wj.AddInjector("test_blockchain", injectTestBlockchain)
+	wj.AddEagerInjector("test_server", injectTestServer)
+	wj.SetActivatingModules([]string{
+		"test_database",
+		"test_blockchain",
+		"test_server",
+	})
+
+	err := wj.Validate()
+	assert.NoError(t, err, "Failed to Validate()")
+	assert.False(t, called)
+	assert.Empty(t, wj.Spans())
+}
+
+func TestValidateProblems(t *testing.T) {
+	wj := New()
+	wj.AddInjector("test_database", injectTestDatabase)
+	wj.AddInjector("test_other_database", injectOtherTestDatabase)
+	wj.AddInjector("test_blockchain", injectTestBlockchain)
+	wj.AddEagerInjector("test_server", injectInvalidSignatureTestServer)
+	wj.AddEagerInjector("test_inactive", injectTestDatabase)
+	wj.SetActivatingModules([]string{
+		"test_database",
+		"test_other_database",
+		"test_blockchain",
+		"test_server",
+		"test_missing",
+	})
+
+	err := wj.Validate()
+	var validationErr *ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Len(t, validationErr.Problems, 4)
+	assert.True(t, errors.Is(err, ErrAmbiguousProvider))
+	assert.True(t, errors.Is(err, ErrInvalidInjectorSignature))
+	assert.True(t, errors.Is(err, ErrNoInjector))
+	assert.True(t, errors.Is(err, ErrModuleNotActivated))
+	assert.False(t, errors.Is(err, ErrDependencyCycle))
+
+	modules := []string{}
+	for _, problem := range validationErr.Problems {
+		var injectionErr *InjectionError
+		assert.True(t, errors.As(problem, &injectionErr))
+		modules = append(modules, injectionErr.Module)
+	}
+	assert.ElementsMatch(t, []string{
+		"test_blockchain", "test_server", "test_missing", "test_inactive"}, modules)
+	assert.Contains(t, err.Error(), "4 problems found")
+	assert.Contains(t, err.Error(), "test_database, test_other_database")
+	assert.Empty(t, wj.Spans())
+}
+
+func TestValidateMissingProvider(t *testing.T) {
+	wj := New()
+	wj.AddEagerInjector("test_blockchain", injectTestBlockchain)
+	wj.SetActivatingModules([]string{"test_blockchain"})
+
+	err := wj.Validate()
+	assert.True(t, errors.Is(err, ErrNoInjector))
+	assert.Contains(t, err.Error(), "wirejacket.testDatabase")
+}
+
+func TestValidateDependencyCycle(t *testing.T) {
+	wj := New()
+	wj.AddInjector("test_database", injectSelfCyclicTestDatabase)
+	wj.AddInjector("test_blockchain", injectCyclicTestBlockchain)
+	wj.AddEagerInjector("test_server", injectTestServer)
+	wj.SetActivatingModules([]string{
+		"test_database",
+		"test_blockchain",
+		"test_server",
+	})
+
+	err := wj.Validate()
+	var validationErr *ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Len(t, validationErr.Problems, 2)
+	for _, problem := range validationErr.Problems {
+		var cycleErr *CycleError
+		assert.True(t, errors.As(problem, &cycleErr))
+	}
+	assert.True(t, errors.Is(err, ErrDependencyCycle))
+}
+
+func TestValidateNothingToWire(t *testing.T) {
+	wj := New()
+	wj.SetActivatingModules([]string{})
+
+	err := wj.Validate()
+	var validationErr *ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Len(t, validationErr.Problems, 2)
+	assert.True(t, errors.Is(err, ErrNoInjector))
+	assert.True(t, errors.Is(err, ErrModuleNotActivated))
+	assert.Contains(t, err.Error(), "no injectors to wire")
+	assert.Contains(t, err.Error(), "no activating modules to wire")
+
+	wj.AddInjector("test_database", injectTestDatabase)
+	err = wj.Validate()
+	assert.True(t, errors.Is(err, ErrModuleNotActivated))
+	assert.Equal(t, wj.DoWire().Error(), validationErr.Problems[1].Error())
+}
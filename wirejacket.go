@@ -2,10 +2,13 @@
 package wirejacket
 
 import (
+	"context"
 	"fmt"
-	"log"
 	"reflect"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	viperjacket "github.com/bang9211/viper-jacket"
 	"github.com/bang9211/wire-jacket/internal/utils"
@@ -14,20 +17,56 @@ import (
 const DefaultConfigName = "viperjacket"
 const DefaultModulesKey = "modules"
 
-// All the module should have Close().
+// InjectTimeoutKeySuffix is the suffix of the config key of the
+// timeout to inject a module, '{module_name}_inject_timeout'.
+const InjectTimeoutKeySuffix = "_inject_timeout"
+
+// Module is a module closing itself with Close().
+// Modules don't have to implement it. A module is closed with the
+// closer set by SetCloser or SetCloserByType, or the first method
+// it has of Close() error, Shutdown(context.Context) error, Close()
+// and Stop(). A module having none of them is not closed.
 type Module interface {
 	// Close closes module gracefully.
 	Close() error
 }
 
 // WireJacket structure. the jacket of the wires(injectors).
+// All the methods of WireJacket are safe for concurrent use.
 type WireJacket struct {
-	config                 viperjacket.Config
-	injectors              map[string]interface{}
-	eagerInjectors         map[string]interface{}
-	modules                map[string]Module
-	sortedModulesByCreated []Module
-	activatingModuleNames  []string
+	mu                    sync.Mutex
+	config                viperjacket.Config
+	injectors             map[string]interface{}
+	eagerInjectors        map[string]interface{}
+	modules               map[string]interface{}
+	cleanups              map[string]func()
+	closers               map[string]CloserFunc
+	typeClosers           []typeCloser
+	dependencies          map[string][]string
+	createdModuleNames    []string
+	creatingModules       map[string]*moduleCall
+	initializedModules    map[string]bool
+	startedModules        map[string]bool
+	startedModuleNames    []string
+	supervisions          map[string]*supervision
+	runFailures           chan error
+	listeners             []Listener
+	logger                atomic.Value
+	spans                 []Span
+	attempts              int
+	wired                 bool
+	closed                bool
+	activatingModuleNames []string
+	concurrency           int
+}
+
+// moduleCall is an in-flight creation of a module.
+// done is closed when the creation is finished with err.
+// dependencyNames are the modules being injected to it.
+type moduleCall struct {
+	done            chan struct{}
+	err             error
+	dependencyNames []string
 }
 
 // New creates empty WireJacket.
@@ -58,11 +97,19 @@ type WireJacket struct {
 func New() *WireJacket {
 	viperJacket := viperjacket.GetOrCreate()
 	wj := &WireJacket{
-		config:                 viperJacket,
-		injectors:              map[string]interface{}{},
-		eagerInjectors:         map[string]interface{}{},
-		modules:                map[string]Module{DefaultConfigName: viperJacket},
-		sortedModulesByCreated: []Module{viperJacket},
+		config:             viperJacket,
+		injectors:          map[string]interface{}{},
+		eagerInjectors:     map[string]interface{}{},
+		modules:            map[string]interface{}{DefaultConfigName: viperJacket},
+		cleanups:           map[string]func(){},
+		closers:            map[string]CloserFunc{},
+		dependencies:       map[string][]string{},
+		createdModuleNames: []string{DefaultConfigName},
+		creatingModules:    map[string]*moduleCall{},
+		initializedModules: map[string]bool{},
+		startedModules:     map[string]bool{},
+		supervisions:       map[string]*supervision{},
+		runFailures:        make(chan error, 1),
 	}
 	wj.activatingModuleNames = wj.readActivatingModules("")
 	wj.activatingModuleNames = append(wj.activatingModuleNames, DefaultConfigName)
@@ -101,11 +148,19 @@ func New() *WireJacket {
 func NewWithServiceName(serviceName string) *WireJacket {
 	viperJacket := viperjacket.GetOrCreate()
 	wj := &WireJacket{
-		config:                 viperJacket,
-		injectors:              map[string]interface{}{},
-		eagerInjectors:         map[string]interface{}{},
-		modules:                map[string]Module{DefaultConfigName: viperJacket},
-		sortedModulesByCreated: []Module{viperJacket},
+		config:             viperJacket,
+		injectors:          map[string]interface{}{},
+		eagerInjectors:     map[string]interface{}{},
+		modules:            map[string]interface{}{DefaultConfigName: viperJacket},
+		cleanups:           map[string]func(){},
+		closers:            map[string]CloserFunc{},
+		dependencies:       map[string][]string{},
+		createdModuleNames: []string{DefaultConfigName},
+		creatingModules:    map[string]*moduleCall{},
+		initializedModules: map[string]bool{},
+		startedModules:     map[string]bool{},
+		supervisions:       map[string]*supervision{},
+		runFailures:        make(chan error, 1),
 	}
 	wj.activatingModuleNames = wj.readActivatingModules(serviceName)
 	wj.activatingModuleNames = append(wj.activatingModuleNames, DefaultConfigName)
@@ -137,7 +192,9 @@ func (wj *WireJacket) readActivatingModules(serviceName string) []string {
 // module's name is used as key of injector maps.
 // It overwrites list of modules to activate.
 func (wj *WireJacket) SetActivatingModules(moduleNames []string) {
-	wj.activatingModuleNames = moduleNames
+	wj.mu.Lock()
+	defer wj.mu.Unlock()
+	wj.activatingModuleNames = append([]string{}, moduleNames...)
 	wj.activatingModuleNames = append(wj.activatingModuleNames, DefaultConfigName)
 }
 
@@ -173,8 +230,11 @@ func (wj *WireJacket) SetActivatingModules(moduleNames []string) {
 //	}
 //
 // injectors will be injected lazily.
+// injectors is copied, changing it later doesn't affect WireJacket.
 func (wj *WireJacket) SetInjectors(injectors map[string]interface{}) *WireJacket {
-	wj.injectors = injectors
+	wj.mu.Lock()
+	defer wj.mu.Unlock()
+	wj.injectors = copyInjectors(injectors)
 	return wj
 }
 
@@ -210,11 +270,22 @@ func (wj *WireJacket) SetInjectors(injectors map[string]interface{}) *WireJacket
 //	}
 //
 // injectors will be injected eagerly.
+// injectors is copied, changing it later doesn't affect WireJacket.
 func (wj *WireJacket) SetEagerInjectors(injectors map[string]interface{}) *WireJacket {
-	wj.eagerInjectors = injectors
+	wj.mu.Lock()
+	defer wj.mu.Unlock()
+	wj.eagerInjectors = copyInjectors(injectors)
 	return wj
 }
 
+func copyInjectors(injectors map[string]interface{}) map[string]interface{} {
+	copied := map[string]interface{}{}
+	for moduleName, injector := range injectors {
+		copied[moduleName] = injector
+	}
+	return copied
+}
+
 // getInjector gets injector in eagerInjectors or injectors
 func (wj *WireJacket) getInjector(moduleName string) interface{} {
 	injector := wj.eagerInjectors[moduleName]
@@ -243,6 +314,8 @@ func (wj *WireJacket) getInjectors() map[string]interface{} {
 // AddInjector adds injector function to the lazy injection list.
 func (wj *WireJacket) AddInjector(moduleName string, injector interface{}) {
 	if reflect.TypeOf(injector).Kind() == reflect.Func {
+		wj.mu.Lock()
+		defer wj.mu.Unlock()
 		wj.injectors[moduleName] = injector
 	}
 }
@@ -250,179 +323,488 @@ func (wj *WireJacket) AddInjector(moduleName string, injector interface{}) {
 // AddInjector adds injector function to the eager injection list.
 func (wj *WireJacket) AddEagerInjector(moduleName string, injector interface{}) {
 	if reflect.TypeOf(injector).Kind() == reflect.Func {
+		wj.mu.Lock()
+		defer wj.mu.Unlock()
 		wj.eagerInjectors[moduleName] = injector
 	}
 }
 
 // DoWire does wiring of wires(injectors).
-// It calls eagerInjectors as finding(if no exists, loading) and injecting dependencies.
+// It builds a wiring plan of eagerInjectors and their dependencies from
+// the injector signatures first, then calls the injectors in the plan.
+// Dependencies are always created before the modules using them, and
+// the order of activating modules decides the rest. So the order of
+// creation and the chosen implementations are the same on every run.
 func (wj *WireJacket) DoWire() error {
+	return wj.DoWireContext(context.Background())
+}
+
+// DoWireContext is DoWire with ctx. If ctx is done, no more injectors
+// are called and the injectors running are abandoned.
+// The injectors having context.Context as the first parameter get ctx.
+//
+// The timeout of each injector can be set in config with
+// '{module_name}_inject_timeout'. If an injector doesn't return in
+// time, its context is canceled and InjectionError of the module
+// wrapping context.DeadlineExceeded is returned.
+//
+// timeout example in app.conf
+//
+// mockup_database_inject_timeout=5s
+//
+// DoWireContext returns when all the eager modules and their
+// dependencies are ready, see ReadySignaler.
+// The spans of the previous wiring are dropped, see Spans.
+func (wj *WireJacket) DoWireContext(ctx context.Context) error {
+	wj.mu.Lock()
+	if problems := wj.checkWirable(); len(problems) > 0 {
+		wj.mu.Unlock()
+		return problems[0]
+	}
+	eagerModuleNames := []string{}
+	for moduleName := range wj.eagerInjectors {
+		eagerModuleNames = append(eagerModuleNames, moduleName)
+	}
+	wj.sortByActivatingOrder(eagerModuleNames)
+	// the trace starts again, not to mix the spans of the attempts.
+	wj.spans = nil
+	wj.mu.Unlock()
+
+	wj.log().Debug("wiring eager modules", "modules", eagerModuleNames)
+	if err := wj.loadModules(ctx, eagerModuleNames); err != nil {
+		return err
+	}
+
+	wj.mu.Lock()
+	wj.wired = true
+	wj.closed = false
+	wj.mu.Unlock()
+	return nil
+}
+
+// checkWirable returns the reasons DoWire refuses to wire, if there
+// are no injectors or no activating modules. wj.mu should be held.
+func (wj *WireJacket) checkWirable() []error {
+	problems := []error{}
 	if len(wj.getInjectors()) == 0 {
-		return fmt.Errorf("no injectors to wire")
+		problems = append(problems, fmt.Errorf("%w : no injectors to wire", ErrNoInjector))
 	}
 	if len(wj.activatingModuleNames) == 1 { //default viperjacket
-		return fmt.Errorf("no activating modules to wire")
+		problems = append(problems, fmt.Errorf("%w : no activating modules to wire", ErrModuleNotActivated))
+	}
+	return problems
+}
+
+// rollback closes createdModuleNames in reverse-dependency order and
+// forgets them, so a failed wiring leaves nothing alive.
+// The modules injected to another module meanwhile, by another
+// goroutine, are kept alive for it.
+// It returns cause if all of them are closed successfully.
+// Otherwise, it returns RollbackError with cause and the close errors.
+func (wj *WireJacket) rollback(createdModuleNames []string, cause error) error {
+	wj.mu.Lock()
+	closingModules := []*closingModule{}
+	for _, moduleName := range wj.closeOrder(wj.unusedModules(createdModuleNames)) {
+		closingModules = append(closingModules, wj.closingModuleOf(moduleName))
+		delete(wj.modules, moduleName)
+		delete(wj.cleanups, moduleName)
+		delete(wj.dependencies, moduleName)
+		wj.createdModuleNames = utils.RemoveElement(wj.createdModuleNames, moduleName)
+	}
+	wj.mu.Unlock()
+
+	closeErrors := wj.closeModules(context.Background(), closingModules)
+	if len(closeErrors) > 0 {
+		return &RollbackError{Cause: cause, CloseErrors: closeErrors}
+	}
+	return cause
+}
+
+// unusedModules returns moduleNames except the modules depended on by
+// the modules not in moduleNames, created or being created, and their
+// dependencies. wj.mu should be held.
+func (wj *WireJacket) unusedModules(moduleNames []string) []string {
+	unused := map[string]bool{}
+	for _, moduleName := range moduleNames {
+		unused[moduleName] = true
+	}
+	dependencyNamesOf := map[string][]string{}
+	for moduleName, dependencyNames := range wj.dependencies {
+		dependencyNamesOf[moduleName] = dependencyNames
+	}
+	for moduleName, call := range wj.creatingModules {
+		dependencyNamesOf[moduleName] = call.dependencyNames
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for moduleName, dependencyNames := range dependencyNamesOf {
+			if unused[moduleName] {
+				continue
+			}
+			for _, dependencyName := range dependencyNames {
+				if unused[dependencyName] {
+					unused[dependencyName] = false
+					changed = true
+				}
+			}
+		}
+	}
+
+	result := []string{}
+	for _, moduleName := range moduleNames {
+		if unused[moduleName] {
+			result = append(result, moduleName)
+		}
+	}
+	return result
+}
+
+// loadModules creates moduleNames and their dependencies following
+// the wiring plan. If it fails, the modules created by it are rolled back.
+// Modules another goroutine is creating are waited for, not created again.
+func (wj *WireJacket) loadModules(ctx context.Context, moduleNames []string) error {
+	wj.mu.Lock()
+	plan, err := wj.buildWiringPlan(moduleNames)
+	concurrency := wj.concurrency
+	wj.attempts++
+	for _, node := range plan {
+		node.attempt = wj.attempts
+	}
+	wj.mu.Unlock()
+	if err != nil {
+		wj.log().Error("failed to plan wiring", "modules", moduleNames, "error", err)
+		return err
+	}
+	for _, node := range plan {
+		for i, dependencyName := range node.dependencyNames {
+			wj.log().Debug("chose injector of dependency",
+				"module", node.moduleName,
+				"type", node.moduleType.String(),
+				"dependency", dependencyName,
+				"dependency_type", node.dependencyTypes[i].String())
+		}
 	}
-	for moduleName, eagerInjector := range wj.eagerInjectors {
-		err := wj.loadModule(moduleName, eagerInjector)
+
+	if concurrency > 1 {
+		createdModuleNames, failure := wj.createModulesParallel(ctx, plan, concurrency)
+		if failure != nil {
+			return wj.rollback(createdModuleNames, &InjectionError{
+				Module:         failure.node.moduleName,
+				DependencyPath: failure.node.dependencyPath,
+				Cause:          failure.err,
+			})
+		}
+		return nil
+	}
+
+	createdModuleNames := []string{}
+	for _, node := range plan {
+		created, err := wj.createModule(ctx, node)
+		if created {
+			createdModuleNames = append(createdModuleNames, node.moduleName)
+		}
 		if err != nil {
-			return fmt.Errorf("[%s] %s", moduleName, err)
+			return wj.rollback(createdModuleNames, &InjectionError{
+				Module:         node.moduleName,
+				DependencyPath: node.dependencyPath,
+				Cause:          err,
+			})
 		}
 	}
 
 	return nil
 }
 
-func (wj *WireJacket) loadModule(moduleName string, injector interface{}) error {
+// createModule calls the injector of node with its dependencies.
+// All the dependencies of node should be created already.
+// If another goroutine is creating the module, it waits and shares
+// the result. created is true only if this call created the module.
+func (wj *WireJacket) createModule(
+	ctx context.Context,
+	node *wiringNode) (created bool, err error) {
+	wj.mu.Lock()
 	//already exists
-	if wj.modules[moduleName] != nil {
-		return nil
+	if wj.modules[node.moduleName] != nil {
+		wj.mu.Unlock()
+		return false, nil
 	}
-	if !utils.IsContain(wj.activatingModuleNames, moduleName) {
-		return fmt.Errorf("no activating module name for injector(%s), in %s",
-			moduleName,
-			wj.activatingModuleNames)
+	// being created by another goroutine
+	if call := wj.creatingModules[node.moduleName]; call != nil {
+		wj.mu.Unlock()
+		select {
+		case <-call.done:
+			return false, call.err
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
 	}
-
+	call := &moduleCall{
+		done:            make(chan struct{}),
+		err:             fmt.Errorf("injector of module(%s) panicked", node.moduleName),
+		dependencyNames: node.dependencyNames,
+	}
+	wj.creatingModules[node.moduleName] = call
 	// get dependencies
-	injectorFunc := reflect.ValueOf(injector)
-	dependencies, err := wj.getDependencies(moduleName, injectorFunc.Type())
+	dependencies, err := wj.getDependencies(node)
+	timeout := wj.config.GetDuration(node.moduleName+InjectTimeoutKeySuffix, 0)
+	readyTimeout := wj.config.GetDuration(node.moduleName+ReadyTimeoutKeySuffix, 0)
+	wj.mu.Unlock()
+
+	defer func() {
+		wj.mu.Lock()
+		delete(wj.creatingModules, node.moduleName)
+		wj.mu.Unlock()
+		close(call.done)
+	}()
 	if err != nil {
-		return err
+		call.err = err
+		return false, err
+	}
+	for _, dependencyName := range node.dependencyNames {
+		wj.emit(Event{Type: EventDependencyResolved, Module: node.moduleName,
+			Dependency: dependencyName})
 	}
 
 	// call injector
-	returnVal := injectorFunc.Call(dependencies)
-	module, err := wj.checkInjectionResult(returnVal)
-	if err != nil {
-		return err
+	wj.emit(Event{Type: EventInjectorStarted, Module: node.moduleName})
+	start := time.Now()
+	result := wj.callInjector(ctx, node, dependencies, timeout)
+	call.err = result.err
+	if result.err != nil {
+		duration := time.Since(start)
+		wj.recordSpan(node, start, duration, result.err)
+		wj.emit(Event{Type: EventInjectorFailed, Module: node.moduleName,
+			Duration: duration, Err: result.err})
+		wj.log().Error("failed to inject module",
+			"module", node.moduleName,
+			"type", node.moduleType.String(),
+			"duration", duration,
+			"error", result.err)
+		return false, result.err
+	}
+
+	// wait for the module to be ready
+	if err := waitReady(ctx, result.module, readyTimeout); err != nil {
+		call.err = fmt.Errorf("%w : %s", ErrModuleNotReady, err)
+		duration := time.Since(start)
+		wj.recordSpan(node, start, duration, call.err)
+		wj.emit(Event{Type: EventInjectorFailed, Module: node.moduleName,
+			Duration: duration, Err: call.err})
+		wj.log().Error("module is not ready",
+			"module", node.moduleName,
+			"type", fmt.Sprintf("%T", result.module),
+			"duration", duration,
+			"error", call.err)
+		wj.discardModule(node.moduleName, result)
+		return false, call.err
 	}
+	duration := time.Since(start)
+	wj.recordSpan(node, start, duration, nil)
+	wj.emit(Event{Type: EventInjectorSucceeded, Module: node.moduleName,
+		Duration: duration})
+	wj.log().Debug("injected module",
+		"module", node.moduleName,
+		"type", fmt.Sprintf("%T", result.module),
+		"duration", duration)
 
 	// set module
-	wj.modules[moduleName] = module
-	pushModule(&wj.sortedModulesByCreated, module)
+	wj.mu.Lock()
+	wj.modules[node.moduleName] = result.module
+	if result.cleanup != nil {
+		wj.cleanups[node.moduleName] = result.cleanup
+	}
+	wj.dependencies[node.moduleName] = node.dependencyNames
+	wj.createdModuleNames = append(wj.createdModuleNames, node.moduleName)
+	wj.closed = false
+	wj.mu.Unlock()
 
-	return nil
+	return true, nil
 }
 
-func (wj *WireJacket) getDependencies(
-	moduleName string,
-	injectorFuncType reflect.Type) ([]reflect.Value, error) {
-	dependencyTypeList := wj.getDependencyTypeList(injectorFuncType)
-
-	dependencies, err := wj.loadAndGetDependencies(moduleName, dependencyTypeList)
-	if err != nil {
-		return nil, err
+// discardModule closes the module of result not to be used.
+func (wj *WireJacket) discardModule(moduleName string, result injectionResult) {
+	wj.mu.Lock()
+	cm := &closingModule{
+		name:    moduleName,
+		module:  result.module,
+		closer:  wj.closerOf(moduleName, result.module),
+		cleanup: result.cleanup,
 	}
+	wj.mu.Unlock()
+	start := time.Now()
+	err := cm.close(context.Background())
+	wj.emitClosed(cm, time.Since(start), err)
+}
 
-	return dependencies, nil
+// injectionResult is the module returned by an injector with
+// its cleanup function.
+type injectionResult struct {
+	module  interface{}
+	cleanup func()
+	err     error
 }
 
-func (wj *WireJacket) loadAndGetDependencies(
-	moduleName string,
-	dependencyTypeList []reflect.Type) ([]reflect.Value, error) {
-	dependencies := []reflect.Value{}
-	for _, dependencyType := range dependencyTypeList {
-		dependencyPtr := wj.findDependency(dependencyType)
-		if dependencyPtr != nil {
-			dependencies = append(dependencies, *dependencyPtr)
-		} else {
-			// find injector to create dependency (return type check)
-			moduleName, injector := wj.findInjector(dependencyType)
-			if injector == nil {
-				return nil, fmt.Errorf("failed to find injector of dependency(%s)", moduleName)
-			}
+// callInjector calls the injector of node and returns the module.
+// If ctx can be done or timeout is set, the injector runs in its own
+// goroutine and is abandoned when ctx is done or timeout is passed.
+// The module returned by an abandoned injector is closed.
+func (wj *WireJacket) callInjector(
+	ctx context.Context,
+	node *wiringNode,
+	dependencies []reflect.Value,
+	timeout time.Duration) injectionResult {
+	if err := ctx.Err(); err != nil {
+		return injectionResult{err: err}
+	}
+	parent := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if node.withContext {
+		dependencies = append([]reflect.Value{reflect.ValueOf(ctx)}, dependencies...)
+	}
 
-			// load dependency using injector
-			err := wj.loadModule(moduleName, injector)
-			if err != nil {
-				return nil, fmt.Errorf("failed to load module of dependency(%s)", moduleName)
-			}
+	if ctx.Done() == nil {
+		return wj.invokeInjector(node, dependencies)
+	}
 
-			// add loaded dependency by injector
-			dependencies = append(dependencies, reflect.ValueOf(wj.modules[moduleName]))
+	results := make(chan injectionResult, 1)
+	go func() {
+		results <- wj.invokeInjector(node, dependencies)
+	}()
+
+	select {
+	case result := <-results:
+		return result
+	case <-ctx.Done():
+		go func() {
+			result := <-results
+			if result.err == nil {
+				wj.discardModule(node.moduleName, result)
+			}
+		}()
+		if parent.Err() == nil {
+			return injectionResult{err: fmt.Errorf("%w : injector didn't return in %s",
+				ctx.Err(), timeout)}
 		}
+		return injectionResult{err: ctx.Err()}
 	}
+}
 
-	return dependencies, nil
+// invokeInjector calls the injector of node with dependencies.
+// A panic of the injector is returned as the error.
+func (wj *WireJacket) invokeInjector(
+	node *wiringNode,
+	dependencies []reflect.Value) (result injectionResult) {
+	defer func() {
+		if r := recover(); r != nil {
+			result = injectionResult{err: fmt.Errorf("injector panicked : %v", r)}
+		}
+	}()
+	injectorFunc := reflect.ValueOf(node.injector)
+	return wj.checkInjectionResult(node.moduleName, injectorFunc.Call(dependencies))
 }
 
-func (wj *WireJacket) findInjector(dependencyType reflect.Type) (string, interface{}) {
-	injectors := wj.getInjectors()
-	for moduleName, injector := range injectors {
-		injectorFunc := reflect.ValueOf(injector)
-		injectorFuncType := injectorFunc.Type()
-		if injectorFuncType.NumOut() > 0 &&
-			injectorFuncType.Out(0).Name() == dependencyType.Name() &&
-			injectorFuncType.Out(0).PkgPath() == dependencyType.PkgPath() {
-			return moduleName, injector
+// getDependencies returns the values to call the injector of node with.
+func (wj *WireJacket) getDependencies(node *wiringNode) ([]reflect.Value, error) {
+	dependencies := []reflect.Value{}
+	for _, dependencyName := range node.dependencyNames {
+		dependency := wj.modules[dependencyName]
+		if dependency == nil {
+			return nil, fmt.Errorf("%w : dependency(%s) is not created",
+				ErrNoInjector, dependencyName)
 		}
+		dependencies = append(dependencies, reflect.ValueOf(dependency))
 	}
 
-	return "", nil
+	return dependencies, nil
+}
+
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+// hasContextParam reports whether the first parameter of injector is
+// context.Context. It is passed by WireJacket, not a dependency.
+func hasContextParam(injectorFuncType reflect.Type) bool {
+	return injectorFuncType.NumIn() > 0 && injectorFuncType.In(0) == contextType
 }
 
 func (wj *WireJacket) getDependencyTypeList(injectorFuncType reflect.Type) []reflect.Type {
 	typeList := []reflect.Type{}
-	for i := 0; i < injectorFuncType.NumIn(); i++ {
+	i := 0
+	if hasContextParam(injectorFuncType) {
+		i = 1
+	}
+	for ; i < injectorFuncType.NumIn(); i++ {
 		dependency := injectorFuncType.In(i)
 		typeList = append(typeList, dependency)
 	}
 	return typeList
 }
 
-func (wj *WireJacket) findDependency(dependencyType reflect.Type) *reflect.Value {
-	for moduleName, module := range wj.modules {
-		if utils.IsContain(wj.activatingModuleNames, moduleName) {
-			moduleValue := reflect.ValueOf(module)
-			if moduleValue.CanConvert(dependencyType) {
-				return &moduleValue
-			}
-		}
-	}
-	return nil
-}
-
-func (wj *WireJacket) checkInjectionResult(returnVal []reflect.Value) (Module, error) {
-	if len(returnVal) != 1 && len(returnVal) != 2 {
-		return nil, fmt.Errorf(
-			"invalid inject function format len(return) : %d", len(returnVal))
+// checkInjectionResult checks the values returned by the injector of
+// moduleName and returns the module with its cleanup function.
+// If injector returned error, it is returned as is.
+// A nil module without error is ErrNilModule.
+func (wj *WireJacket) checkInjectionResult(
+	moduleName string,
+	returnVal []reflect.Value) injectionResult {
+	if len(returnVal) < 1 || len(returnVal) > 3 {
+		return injectionResult{err: fmt.Errorf("%w : len(return) : %d",
+			ErrInvalidInjectorSignature, len(returnVal))}
 	}
-	var module Module
-	var ok bool
-	if len(returnVal) == 1 { // return (module)
-		if !returnVal[0].IsValid() || !returnVal[0].CanInterface() {
-			return nil, fmt.Errorf(
-				"returnVal(%s) can't be interface",
-				returnVal[0],
-			)
-		}
-		module, ok = returnVal[0].Interface().(Module)
-		if !ok {
-			return nil, fmt.Errorf(
-				"failed to cast returnVal(%s) to Module", returnVal[0])
+	last := returnVal[len(returnVal)-1]
+	if len(returnVal) == 3 || (len(returnVal) == 2 && last.Kind() != reflect.Func) {
+		// return (module, error) or (module, cleanup, error)
+		if !last.IsValid() || !last.CanInterface() {
+			return injectionResult{err: fmt.Errorf("%w : failed to cast error(%s) to interface",
+				ErrInvalidInjectorSignature, last)}
 		}
-	} else { // return (module, error)
-		if !returnVal[1].IsValid() || !returnVal[1].CanInterface() {
-			return nil, fmt.Errorf(
-				"failed to cast error(%s) to interface", returnVal[1])
-		}
-		err := returnVal[1].Interface()
-		if err != nil {
-			return nil, fmt.Errorf(
-				"failed to inject : %s", err)
+		if last.Interface() != nil {
+			err, ok := last.Interface().(error)
+			if !ok {
+				return injectionResult{err: fmt.Errorf("%w : returnVal(%s) is not error",
+					ErrInvalidInjectorSignature, last)}
+			}
+			return injectionResult{err: err}
 		}
-		if !returnVal[0].IsValid() || !returnVal[0].CanInterface() {
-			return nil, fmt.Errorf(
-				"failed to cast returnVal(%s) to interface", returnVal[0])
+	}
+	var cleanup func()
+	if len(returnVal) > 1 && returnVal[1].Kind() == reflect.Func {
+		// return (module, cleanup) or (module, cleanup, error)
+		if !returnVal[1].CanInterface() {
+			return injectionResult{err: fmt.Errorf("%w : failed to cast cleanup(%s) to interface",
+				ErrInvalidInjectorSignature, returnVal[1])}
 		}
-		module, ok = returnVal[0].Interface().(Module)
-		if !ok {
-			return nil, fmt.Errorf(
-				"failed to cast returnVal(%s) to Module", returnVal[0])
+		cleanup, _ = returnVal[1].Interface().(func())
+	}
+	if !returnVal[0].IsValid() || !returnVal[0].CanInterface() {
+		return injectionResult{err: fmt.Errorf("%w : failed to cast returnVal(%s) to interface",
+			ErrInvalidInjectorSignature, returnVal[0])}
+	}
+	if isNilValue(returnVal[0]) {
+		if cleanup != nil {
+			cleanup()
 		}
+		return injectionResult{err: fmt.Errorf("%w : injector of module(%s) returned nil",
+			ErrNilModule, moduleName)}
+	}
+	return injectionResult{module: returnVal[0].Interface(), cleanup: cleanup}
+}
+
+// isNilValue reports whether v is nil or a nil pointer, map, slice,
+// function, channel or interface.
+func isNilValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func,
+		reflect.Chan, reflect.Interface, reflect.UnsafePointer:
+		return v.IsNil()
 	}
-	return module, nil
+	return false
 }
 
 // GetConfig returns config object.
@@ -432,18 +814,51 @@ func GetConfig() viperjacket.Config {
 
 // GetModule finds module using moduleName and returns module if exists.
 // If no exists, it tries to create module using injector and returns.
+// It returns nil if the module can't be created. Use GetModuleE to get
+// the reason.
 func (wj *WireJacket) GetModule(moduleName string) interface{} {
+	module, _ := wj.GetModuleE(moduleName)
+	return module
+}
+
+// GetModuleE is GetModule returning the error of injection.
+// The error is InjectionError that has the failed module and
+// the dependency path to it.
+func (wj *WireJacket) GetModuleE(moduleName string) (interface{}, error) {
+	return wj.GetModuleContext(context.Background(), moduleName)
+}
+
+// GetModuleContext is GetModuleE with ctx. ctx is passed to the
+// injectors having context.Context as the first parameter.
+// See DoWireContext for the cancellation and the timeouts.
+func (wj *WireJacket) GetModuleContext(
+	ctx context.Context,
+	moduleName string) (interface{}, error) {
+	wj.mu.Lock()
 	module := wj.modules[moduleName]
+	wj.mu.Unlock()
 	if module != nil {
-		return module
+		return module, nil
 	}
-	injector := wj.getInjector(moduleName)
-	if injector == nil {
-		return nil
+	wj.log().Debug("loading module lazily", "module", moduleName)
+	err := wj.loadModules(ctx, []string{moduleName})
+	if err != nil {
+		return nil, err
 	}
-	wj.loadModule(moduleName, injector)
 
-	return wj.modules[moduleName]
+	wj.mu.Lock()
+	defer wj.mu.Unlock()
+	return wj.modules[moduleName], nil
+}
+
+// MustGetModule is GetModule panicking if the module can't be created.
+// The panic message has the module name and its dependency path.
+func (wj *WireJacket) MustGetModule(moduleName string) interface{} {
+	module, err := wj.GetModuleE(moduleName)
+	if err != nil {
+		panic(fmt.Sprintf("failed to get module(%s) : %s", moduleName, err))
+	}
+	return module
 }
 
 // GetModuleByType finds module using interfaceType(pointer of interface)
@@ -454,48 +869,131 @@ func (wj *WireJacket) GetModule(moduleName string) interface{} {
 // config := wj.GetModuleByType((*viperjacket.Config)(nil))
 //
 // If no exists, it tries to create module using injector and returns.
-// This may return undesirable results if there are other implementations
-// that may use the same interface. Use only if you are sure that there
-// are no overlapping interfaces.
+// If there are other implementations of the same interface, the one
+// listed first in the activating modules is returned. Created modules
+// are preferred over the ones to create.
+// It returns nil if the module can't be created. Use GetModuleByTypeE
+// to get the reason.
 func (wj *WireJacket) GetModuleByType(interfaceType interface{}) interface{} {
-	if interfaceType == nil {
-		return nil
+	module, _ := wj.GetModuleByTypeE(interfaceType)
+	return module
+}
+
+// GetModuleByTypeE is GetModuleByType returning the error of injection.
+func (wj *WireJacket) GetModuleByTypeE(interfaceType interface{}) (interface{}, error) {
+	if interfaceType == nil || reflect.TypeOf(interfaceType).Kind() != reflect.Ptr {
+		return nil, fmt.Errorf("interfaceType(%T) should be pointer of interface",
+			interfaceType)
 	}
 	moduleType := reflect.TypeOf(interfaceType).Elem()
-	moduleName, injector := wj.findInjector(moduleType)
-	wj.loadModule(moduleName, injector)
-	for _, module := range wj.modules {
-		moduleValue := reflect.ValueOf(module)
-		if moduleValue.CanConvert(moduleType) {
-			return module
-		}
+	wj.mu.Lock()
+	moduleName := wj.findProvider(moduleType)
+	wj.mu.Unlock()
+	if moduleName == "" {
+		return nil, fmt.Errorf("%w : no activated injector of type(%s)",
+			ErrNoInjector, moduleType)
 	}
 
-	return nil
+	return wj.GetModuleE(moduleName)
 }
 
-// Close closes all the modules gracefully
+// MustGetModuleByType is GetModuleByType panicking if the module
+// can't be created.
+func (wj *WireJacket) MustGetModuleByType(interfaceType interface{}) interface{} {
+	module, err := wj.GetModuleByTypeE(interfaceType)
+	if err != nil {
+		panic(fmt.Sprintf("failed to get module of type(%T) : %s", interfaceType, err))
+	}
+	return module
+}
+
+// Close closes all the modules gracefully.
+// Modules are closed in reverse-dependency order. A module is closed
+// before the modules it depends on, so every dependency outlives the
+// modules using it. The cleanup function returned by the injector of
+// a module is called right after the module is closed.
+// See Module for how a module is closed.
+// Close doesn't stop the started modules, use Stop before Close.
+// The closed modules are forgotten, GetModule and DoWire create them
+// again.
+//
+// All the modules are tried to close. If some of them failed, Close
+// returns CloseError having the error of each of them.
 func (wj *WireJacket) Close() error {
-	moduleLen := len(wj.sortedModulesByCreated)
-	for i := 0; i < moduleLen; i++ {
-		module := popModule(&wj.sortedModulesByCreated)
-		err := module.Close()
-		if err != nil {
-			log.Printf("failed to close module(%s) : %s", reflect.ValueOf(module).Type(), err)
+	return wj.CloseContext(context.Background())
+}
+
+// CloseContext is Close with the deadline of ctx.
+// A module is closed with the timeout of '{module_name}_close_timeout'
+// in config(see CloseTimeoutKeySuffix). The module not closed in its
+// timeout is abandoned and the next module is closed.
+// When ctx is done, the module being closed is abandoned and the rest
+// are skipped. The abandoned and skipped modules are reported in
+// CloseError with ctx.Err().
+func (wj *WireJacket) CloseContext(ctx context.Context) error {
+	wj.mu.Lock()
+	closingModules := []*closingModule{}
+	for _, moduleName := range wj.closeOrder(wj.createdModuleNames) {
+		closingModules = append(closingModules, wj.closingModuleOf(moduleName))
+		if moduleName != DefaultConfigName {
+			delete(wj.modules, moduleName)
 		}
+		delete(wj.cleanups, moduleName)
+		delete(wj.dependencies, moduleName)
+	}
+	// the config outlives Close like in New, DoWire can wire again.
+	wj.createdModuleNames = []string{DefaultConfigName}
+	wj.initializedModules = map[string]bool{}
+	wj.startedModules = map[string]bool{}
+	wj.startedModuleNames = []string{}
+	wj.wired = false
+	wj.closed = true
+	wj.mu.Unlock()
+
+	if closeErrors := wj.closeModules(ctx, closingModules); len(closeErrors) > 0 {
+		return &CloseError{Errors: closeErrors}
 	}
-
 	return nil
 }
 
-func pushModule(modules *[]Module, module Module) {
-	*modules = append(*modules, module)
-}
+// closeOrder returns the names of created modules in the order to close.
+// It uses the dependency edges recorded while wiring. Among the modules
+// no other open module depends on, the latest created is closed first.
+// wj.mu should be held.
+func (wj *WireJacket) closeOrder(created []string) []string {
+	openDependents := map[string]int{}
+	for _, moduleName := range created {
+		for _, dependencyName := range wj.dependencies[moduleName] {
+			openDependents[dependencyName]++
+		}
+	}
 
-func popModule(modules *[]Module) Module {
-	ret := (*modules)[0]
-	if len(*modules) > 0 {
-		*modules = (*modules)[1:]
+	order := []string{}
+	closed := map[string]bool{}
+	for len(order) < len(created) {
+		next := ""
+		for i := len(created) - 1; i >= 0; i-- {
+			moduleName := created[i]
+			if !closed[moduleName] && openDependents[moduleName] == 0 {
+				next = moduleName
+				break
+			}
+		}
+		if next == "" {
+			// edges can't be satisfied, close the rest by creation order.
+			for i := len(created) - 1; i >= 0; i-- {
+				if !closed[created[i]] {
+					order = append(order, created[i])
+				}
+			}
+			break
+		}
+		closed[next] = true
+		order = append(order, next)
+		for _, dependencyName := range wj.dependencies[next] {
+			openDependents[dependencyName]--
+		}
 	}
-	return ret
+
+	return order
 }
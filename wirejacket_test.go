@@ -1,10 +1,16 @@
 package wirejacket
 
 import (
+	"context"
+	"errors"
 	"io"
 	"os"
 	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	viperjacket "github.com/bang9211/viper-jacket"
 	"github.com/bang9211/wire-jacket/internal/mockup"
@@ -407,7 +413,12 @@ func TestDoWireFailedInjectorCall(t *testing.T) {
 	wj.AddInjector("mockup_database", mockup.InjectMockupDB)
 	wj.AddInjector("mockup_blockchain", mockup.InjectMockupBlockchain)
 	wj.AddEagerInjector("mockup_explorerserver", mockup.InjectMockupExplorerServer)
-	wj.AddEagerInjector("mockup_restapiserver", mockup.InjectMockupInvalidReturnTest)
+	wj.AddEagerInjector("mockup_restapiserver", func(
+		config viperjacket.Config,
+		blockchain mockup.Blockchain,
+	) (mockup.RESTAPIServer, error, error) {
+		return nil, nil, nil
+	})
 
 	wj.SetActivatingModules([]string{
 		"mockup_database",
@@ -425,35 +436,73 @@ func TestDoWireFailedInjectorCall(t *testing.T) {
 func TestCheckInjectionResult(t *testing.T) {
 	wj := New()
 	values := []reflect.Value{reflect.Value{}}
-	_, err := wj.checkInjectionResult(values)
+	err := wj.checkInjectionResult("test", values).err
 	assert.Error(t, err)
 
+	// any value is a module, even without a way to close.
 	values = []reflect.Value{reflect.ValueOf(true)}
-	_, err = wj.checkInjectionResult(values)
-	assert.Error(t, err)
+	result := wj.checkInjectionResult("test", values)
+	assert.NoError(t, result.err)
+	assert.Equal(t, true, result.module)
 
 	values = []reflect.Value{reflect.Value{}, reflect.ValueOf(true)}
-	_, err = wj.checkInjectionResult(values)
+	err = wj.checkInjectionResult("test", values).err
 	assert.Error(t, err)
 
 	// get dependencies
 	injectorFunc := reflect.ValueOf(mockup.InjectMockupDB)
-	dependencies, err := wj.getDependencies("mockup_database", injectorFunc.Type())
+	dependencies := []reflect.Value{reflect.ValueOf(GetConfig())}
 
 	// call injector
 	returnVal := injectorFunc.Call(dependencies)
 
 	values = []reflect.Value{reflect.Value{}, reflect.Value{}}
-	_, err = wj.checkInjectionResult(values)
+	err = wj.checkInjectionResult("test", values).err
 	assert.Error(t, err)
 
 	values = []reflect.Value{reflect.Value{}, returnVal[1]}
-	_, err = wj.checkInjectionResult(values)
+	err = wj.checkInjectionResult("test", values).err
 	assert.Error(t, err)
 
 	values = []reflect.Value{reflect.ValueOf(true), returnVal[1]}
-	_, err = wj.checkInjectionResult(values)
-	assert.Error(t, err)
+	err = wj.checkInjectionResult("test", values).err
+	assert.NoError(t, err)
+
+	values = []reflect.Value{reflect.Zero(reflect.TypeOf((*testDatabase)(nil)).Elem()), returnVal[1]}
+	err = wj.checkInjectionResult("test", values).err
+	assert.True(t, errors.Is(err, ErrNilModule))
+	assert.Contains(t, err.Error(), "module(test)")
+
+	values = []reflect.Value{reflect.ValueOf((*testDatabaseImpl)(nil))}
+	err = wj.checkInjectionResult("test", values).err
+	assert.True(t, errors.Is(err, ErrNilModule))
+}
+
+func TestGetModuleNilModule(t *testing.T) {
+	calls := 0
+	wj := New()
+	wj.AddInjector("test_database", func(config viperjacket.Config) (testDatabase, error) {
+		calls++
+		return nil, nil
+	})
+	wj.AddInjector("test_blockchain", injectTestBlockchain)
+	wj.SetActivatingModules([]string{"test_database", "test_blockchain"})
+
+	module, err := wj.GetModuleE("test_database")
+	assert.Nil(t, module)
+	assert.True(t, errors.Is(err, ErrNilModule))
+	assert.Contains(t, err.Error(), "module(test_database)")
+
+	_, err = wj.GetModuleE("test_blockchain")
+	assert.True(t, errors.Is(err, ErrNilModule))
+	var injectionErr *InjectionError
+	assert.True(t, errors.As(err, &injectionErr))
+	assert.Equal(t, []string{"test_blockchain", "test_database"}, injectionErr.DependencyPath)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, []string{DefaultConfigName}, wj.createdModuleNames)
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
 }
 
 func TestClose(t *testing.T) {
@@ -498,5 +547,562 @@ func TestCloseErro(t *testing.T) {
 
 	wj.SetActivatingModules([]string{"test"})
 	wj.GetModule("test")
-	assert.NoError(t, wj.Close())
+	err := wj.Close()
+	var closeErr *CloseError
+	assert.True(t, errors.As(err, &closeErr))
+	assert.Len(t, closeErr.Errors, 1)
+	assert.Contains(t, err.Error(), "module(test) : mockup error")
+}
+
+var closedModuleNamesMu sync.Mutex
+var closedModuleNames []string
+
+func resetClosedModuleNames() {
+	closedModuleNamesMu.Lock()
+	defer closedModuleNamesMu.Unlock()
+	closedModuleNames = nil
+}
+
+func getClosedModuleNames() []string {
+	closedModuleNamesMu.Lock()
+	defer closedModuleNamesMu.Unlock()
+	return append([]string(nil), closedModuleNames...)
+}
+
+type closeRecorder struct {
+	name string
+}
+
+func (cr *closeRecorder) Close() error {
+	closedModuleNamesMu.Lock()
+	defer closedModuleNamesMu.Unlock()
+	closedModuleNames = append(closedModuleNames, cr.name)
+	return nil
+}
+
+type testDatabase interface {
+	Query() string
+	Close() error
+}
+
+type testBlockchain interface {
+	Mine() string
+	Close() error
+}
+
+type testServer interface {
+	Serve() error
+	Close() error
+}
+
+type testDatabaseImpl struct{ closeRecorder }
+
+func (tdb *testDatabaseImpl) Query() string { return tdb.name }
+
+type testBlockchainImpl struct{ closeRecorder }
+
+func (tbc *testBlockchainImpl) Mine() string { return tbc.name }
+
+type testServerImpl struct{ closeRecorder }
+
+func (ts *testServerImpl) Serve() error { return nil }
+
+func injectTestDatabase(config viperjacket.Config) (testDatabase, error) {
+	return &testDatabaseImpl{closeRecorder{"test_database"}}, nil
+}
+
+func injectTestBlockchain(db testDatabase) (testBlockchain, error) {
+	return &testBlockchainImpl{closeRecorder{"test_blockchain"}}, nil
+}
+
+func injectTestServer(config viperjacket.Config, blockchain testBlockchain) (testServer, error) {
+	return &testServerImpl{closeRecorder{"test_server"}}, nil
+}
+
+// newTestWireJacket returns WireJacket activating test_database,
+// test_blockchain and eager test_server. Add the injector of the same
+// name to replace one of them.
+func newTestWireJacket() *WireJacket {
+	wj := New()
+	wj.AddInjector("test_database", injectTestDatabase)
+	wj.AddInjector("test_blockchain", injectTestBlockchain)
+	wj.AddEagerInjector("test_server", injectTestServer)
+	wj.SetActivatingModules([]string{
+		"test_database",
+		"test_blockchain",
+		"test_server",
+	})
+	return wj
+}
+
+func TestCloseReverseDependencyOrder(t *testing.T) {
+	wj := newTestWireJacket()
+
+	err := wj.DoWire()
+	assert.NoError(t, err, "Failed to DoWire()")
+	assert.Equal(t, []string{"test_database"}, wj.dependencies["test_blockchain"])
+	assert.Equal(t, []string{DefaultConfigName, "test_blockchain"}, wj.dependencies["test_server"])
+
+	resetClosedModuleNames()
+	assert.Equal(t,
+		[]string{"test_server", "test_blockchain", "test_database", DefaultConfigName},
+		wj.closeOrder(wj.createdModuleNames))
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+	assert.Equal(t,
+		[]string{"test_server", "test_blockchain", "test_database"},
+		getClosedModuleNames())
+	assert.Equal(t, []string{DefaultConfigName}, wj.closeOrder(wj.createdModuleNames))
+}
+
+func TestCloseAndWireAgain(t *testing.T) {
+	wj := newTestWireJacket()
+	err := wj.DoWire()
+	assert.NoError(t, err, "Failed to DoWire()")
+	database := wj.GetModule("test_database")
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+	assert.Equal(t, map[string]interface{}{DefaultConfigName: wj.config}, wj.modules)
+	assert.Empty(t, wj.dependencies)
+	assert.Equal(t, []string{DefaultConfigName}, wj.createdModuleNames)
+
+	// the closed modules are created again.
+	resetClosedModuleNames()
+	err = wj.DoWire()
+	assert.NoError(t, err, "Failed to DoWire()")
+	assert.NotNil(t, wj.modules["test_server"])
+	assert.NotSame(t, database, wj.GetModule("test_database"))
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+	assert.Equal(t,
+		[]string{"test_server", "test_blockchain", "test_database"},
+		getClosedModuleNames())
+}
+
+var errTestInjection = errors.New("test injection error")
+
+type failingCloseDatabaseImpl struct{ testDatabaseImpl }
+
+func (fdb *failingCloseDatabaseImpl) Close() error {
+	fdb.testDatabaseImpl.Close()
+	return errors.New("test close error")
+}
+
+func injectFailingCloseTestDatabase(config viperjacket.Config) (testDatabase, error) {
+	return &failingCloseDatabaseImpl{testDatabaseImpl{closeRecorder{"test_database"}}}, nil
+}
+
+func injectFailingTestServer(config viperjacket.Config, blockchain testBlockchain) (testServer, error) {
+	return nil, errTestInjection
+}
+
+func TestDoWireRollback(t *testing.T) {
+	wj := newTestWireJacket()
+	wj.AddEagerInjector("test_server", injectFailingTestServer)
+
+	resetClosedModuleNames()
+	err := wj.DoWire()
+	assert.Error(t, err)
+	assert.Equal(t, []string{"test_blockchain", "test_database"}, getClosedModuleNames())
+	assert.NotContains(t, wj.modules, "test_database")
+	assert.NotContains(t, wj.modules, "test_blockchain")
+	assert.NotContains(t, wj.dependencies, "test_blockchain")
+	assert.Equal(t, []string{DefaultConfigName}, wj.createdModuleNames)
+
+	// modules are created again in the next attempt.
+	wj.AddEagerInjector("test_server", injectTestServer)
+	err = wj.DoWire()
+	assert.NoError(t, err, "Failed to DoWire()")
+	assert.NotNil(t, wj.modules["test_database"])
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
+
+func TestDoWireRollbackCloseError(t *testing.T) {
+	wj := newTestWireJacket()
+	wj.AddInjector("test_database", injectFailingCloseTestDatabase)
+	wj.AddEagerInjector("test_server", injectFailingTestServer)
+
+	resetClosedModuleNames()
+	err := wj.DoWire()
+	var rollbackErr *RollbackError
+	assert.True(t, errors.As(err, &rollbackErr))
+	assert.Len(t, rollbackErr.CloseErrors, 1)
+	assert.Contains(t, err.Error(), "test close error")
+	assert.Equal(t, []string{"test_blockchain", "test_database"}, getClosedModuleNames())
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
+
+func TestDoWireRollbackPanic(t *testing.T) {
+	wj := newTestWireJacket()
+	wj.AddEagerInjector("test_server", func(
+		config viperjacket.Config, blockchain testBlockchain) (testServer, error) {
+		panic("boom")
+	})
+
+	resetClosedModuleNames()
+	var err error
+	assert.NotPanics(t, func() { err = wj.DoWire() })
+	var injectionErr *InjectionError
+	assert.True(t, errors.As(err, &injectionErr))
+	assert.Equal(t, "test_server", injectionErr.Module)
+	assert.Contains(t, err.Error(), "injector panicked : boom")
+	assert.Equal(t, []string{"test_blockchain", "test_database"}, getClosedModuleNames())
+	assert.Equal(t, []string{DefaultConfigName}, wj.createdModuleNames)
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
+
+func TestGetModuleRollback(t *testing.T) {
+	wj := New()
+	wj.AddInjector("test_database", injectTestDatabase)
+	wj.AddInjector("test_blockchain", injectTestBlockchain)
+	wj.AddInjector("test_server", injectFailingTestServer)
+	wj.SetActivatingModules([]string{
+		"test_database",
+		"test_blockchain",
+		"test_server",
+	})
+
+	resetClosedModuleNames()
+	assert.Nil(t, wj.GetModule("test_server"))
+	assert.Equal(t, []string{"test_blockchain", "test_database"}, getClosedModuleNames())
+	assert.NotContains(t, wj.modules, "test_database")
+
+	err := wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
+
+func TestRollbackKeepsModuleUsedByAnother(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	wj := New()
+	wj.AddInjector("test_database", injectTestDatabase)
+	wj.AddInjector("test_failing_blockchain", func(db testDatabase) (testBlockchain, error) {
+		close(started)
+		<-release
+		return nil, errTestInjection
+	})
+	wj.AddInjector("test_blockchain", injectTestBlockchain)
+	wj.SetActivatingModules([]string{
+		"test_database",
+		"test_failing_blockchain",
+		"test_blockchain",
+	})
+
+	resetClosedModuleNames()
+	failed := make(chan error)
+	go func() {
+		_, err := wj.GetModuleE("test_failing_blockchain")
+		failed <- err
+	}()
+	<-started
+	db := wj.GetModule("test_database")
+	assert.NotNil(t, db)
+	blockchain, err := wj.GetModuleE("test_blockchain")
+	assert.NoError(t, err, "Failed to GetModuleE()")
+	assert.NotNil(t, blockchain)
+
+	close(release)
+	err = <-failed
+	assert.True(t, errors.Is(err, errTestInjection))
+	assert.Empty(t, getClosedModuleNames())
+	assert.Same(t, db, wj.GetModule("test_database"))
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+	assert.Equal(t, []string{"test_blockchain", "test_database"}, getClosedModuleNames())
+}
+
+func TestGetModuleE(t *testing.T) {
+	wj := New()
+	wj.AddInjector("test_database", injectTestDatabase)
+	wj.AddInjector("test_blockchain", injectTestBlockchain)
+	wj.AddInjector("test_server", injectFailingTestServer)
+	wj.SetActivatingModules([]string{"test_database", "test_blockchain", "test_server"})
+
+	blockchain, err := wj.GetModuleE("test_blockchain")
+	assert.NoError(t, err)
+	assert.NotNil(t, blockchain)
+
+	server, err := wj.GetModuleE("test_server")
+	assert.Nil(t, server)
+	assert.True(t, errors.Is(err, errTestInjection))
+
+	_, err = wj.GetModuleE("no_exists")
+	assert.True(t, errors.Is(err, ErrNoInjector))
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
+
+func TestMustGetModule(t *testing.T) {
+	wj := New()
+	wj.AddInjector("test_blockchain", injectTestBlockchain)
+	wj.AddInjector("test_server", injectTestServer)
+	wj.SetActivatingModules([]string{"test_blockchain", "test_server"})
+
+	assert.PanicsWithValue(t,
+		"failed to get module(test_server) : [test_blockchain] "+
+			"no injector : no activated injector of dependency(wirejacket.testDatabase) "+
+			"(dependency path : test_server -> test_blockchain)",
+		func() { wj.MustGetModule("test_server") })
+
+	wj.AddInjector("test_database", injectTestDatabase)
+	wj.SetActivatingModules([]string{"test_database", "test_blockchain", "test_server"})
+	assert.NotPanics(t, func() {
+		assert.NotNil(t, wj.MustGetModule("test_server"))
+	})
+
+	err := wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
+
+func TestGetModuleByTypeE(t *testing.T) {
+	wj := New().
+		SetInjectors(mockup.Injectors).
+		SetEagerInjectors(mockup.EagerInjectors)
+
+	blockchain, err := wj.GetModuleByTypeE((*mockup.Blockchain)(nil))
+	assert.NoError(t, err)
+	assert.NotNil(t, blockchain)
+
+	_, err = wj.GetModuleByTypeE((*io.Writer)(nil))
+	assert.True(t, errors.Is(err, ErrNoInjector))
+	_, err = wj.GetModuleByTypeE(nil)
+	assert.Error(t, err)
+
+	assert.Panics(t, func() { wj.MustGetModuleByType((*io.Writer)(nil)) })
+	assert.NotNil(t, wj.MustGetModuleByType((*mockup.ExplorerServer)(nil)))
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
+
+func TestGetModuleConcurrently(t *testing.T) {
+	var injectorCalls int32
+	injectSlowTestDatabase := func(config viperjacket.Config) (testDatabase, error) {
+		atomic.AddInt32(&injectorCalls, 1)
+		time.Sleep(10 * time.Millisecond)
+		return &testDatabaseImpl{closeRecorder{"test_database"}}, nil
+	}
+	wj := New()
+	wj.AddInjector("test_database", injectSlowTestDatabase)
+	wj.AddInjector("test_blockchain", injectTestBlockchain)
+	wj.AddInjector("test_server", injectTestServer)
+	wj.SetActivatingModules([]string{"test_database", "test_blockchain", "test_server"})
+
+	const goroutines = 16
+	results := make([]interface{}, goroutines)
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%2 == 0 {
+				results[i] = wj.GetModule("test_database")
+			} else {
+				wj.GetModule("test_server")
+				results[i] = wj.GetModule("test_database")
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&injectorCalls))
+	for _, result := range results {
+		assert.NotNil(t, result)
+		assert.Same(t, results[0], result)
+	}
+
+	err := wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
+
+func TestGetModuleConcurrentlySharedError(t *testing.T) {
+	var injectorCalls int32
+	release := make(chan struct{})
+	injectSlowFailingTestDatabase := func(config viperjacket.Config) (testDatabase, error) {
+		atomic.AddInt32(&injectorCalls, 1)
+		<-release
+		return nil, errTestInjection
+	}
+	wj := New()
+	wj.AddInjector("test_database", injectSlowFailingTestDatabase)
+	wj.SetActivatingModules([]string{"test_database"})
+
+	const goroutines = 8
+	errs := make([]error, goroutines)
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = wj.GetModuleE("test_database")
+		}(i)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&injectorCalls))
+	for _, err := range errs {
+		assert.True(t, errors.Is(err, errTestInjection))
+	}
+
+	err := wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
+
+type testContextKey struct{}
+
+func TestDoWireContext(t *testing.T) {
+	wj := New()
+	wj.AddEagerInjector("test_database",
+		func(ctx context.Context, config viperjacket.Config) (testDatabase, error) {
+			return &testDatabaseImpl{closeRecorder{ctx.Value(testContextKey{}).(string)}}, nil
+		})
+	wj.SetActivatingModules([]string{"test_database"})
+
+	ctx := context.WithValue(context.Background(), testContextKey{}, "from_context")
+	err := wj.DoWireContext(ctx)
+	assert.NoError(t, err, "Failed to DoWireContext()")
+	db, err := GetNamed[testDatabase](wj, "test_database")
+	assert.NoError(t, err)
+	assert.Equal(t, "from_context", db.Query())
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
+
+func injectHangingTestDatabase(ctx context.Context, config viperjacket.Config) (testDatabase, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestDoWireContextDeadline(t *testing.T) {
+	wj := New()
+	wj.AddInjector("test_database", injectHangingTestDatabase)
+	wj.AddEagerInjector("test_blockchain", injectTestBlockchain)
+	wj.SetActivatingModules([]string{"test_database", "test_blockchain"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := wj.DoWireContext(ctx)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	var injectionErr *InjectionError
+	assert.True(t, errors.As(err, &injectionErr))
+	assert.Equal(t, "test_database", injectionErr.Module)
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
+
+func TestInjectTimeout(t *testing.T) {
+	t.Setenv("TEST_DATABASE"+strings.ToUpper(InjectTimeoutKeySuffix), "20ms")
+	wj := New()
+	wj.AddInjector("test_database", injectHangingTestDatabase)
+	wj.AddInjector("test_blockchain", injectTestBlockchain)
+	wj.SetActivatingModules([]string{"test_database", "test_blockchain"})
+
+	start := time.Now()
+	_, err := wj.GetModuleE("test_blockchain")
+	assert.Less(t, time.Since(start), time.Second)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Contains(t, err.Error(), "[test_database]")
+	assert.Contains(t, err.Error(), "didn't return in 20ms")
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
+
+func TestGetModuleContextCanceled(t *testing.T) {
+	wj := New()
+	wj.AddInjector("test_database", injectTestDatabase)
+	wj.SetActivatingModules([]string{"test_database"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := wj.GetModuleContext(ctx, "test_database")
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	module, err := wj.GetModuleContext(context.Background(), "test_database")
+	assert.NoError(t, err)
+	assert.NotNil(t, module)
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
+
+// testClient doesn't implement Module, it is released by cleanup.
+type testClient struct {
+	released bool
+}
+
+func TestDoWireCleanup(t *testing.T) {
+	client := &testClient{}
+	cleanups := []string{}
+	wj := New()
+	wj.AddInjector("test_database", func(config viperjacket.Config) (testDatabase, func(), error) {
+		return &testDatabaseImpl{closeRecorder{"test_database"}},
+			func() { cleanups = append(cleanups, "test_database") }, nil
+	})
+	wj.AddInjector("test_client", func(db testDatabase) (*testClient, func()) {
+		return client, func() {
+			client.released = true
+			cleanups = append(cleanups, "test_client")
+		}
+	})
+	wj.AddEagerInjector("test_server", func(config viperjacket.Config, client *testClient) (testServer, error) {
+		return &testServerImpl{closeRecorder{"test_server"}}, nil
+	})
+	wj.SetActivatingModules([]string{"test_database", "test_client", "test_server"})
+
+	err := wj.DoWire()
+	assert.NoError(t, err, "Failed to DoWire()")
+	assert.Same(t, client, wj.GetModule("test_client"))
+
+	resetClosedModuleNames()
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+	assert.True(t, client.released)
+	assert.Equal(t, []string{"test_client", "test_database"}, cleanups)
+	assert.Equal(t, []string{"test_server", "test_database"}, getClosedModuleNames())
+}
+
+func TestDoWireWireCleanupMockup(t *testing.T) {
+	wj := New()
+	wj.AddInjector("mockup_database", mockup.InjectMockupDB)
+	wj.AddInjector("mockup_blockchain", mockup.InjectMockupBlockchain)
+	wj.AddEagerInjector("mockup_restapiserver", mockup.InjectMockupRESTAPIServerWithCleanup)
+	wj.SetActivatingModules([]string{
+		"mockup_database",
+		"mockup_blockchain",
+		"mockup_restapiserver",
+	})
+
+	err := wj.DoWire()
+	assert.NoError(t, err, "Failed to DoWire()")
+	assert.NotNil(t, wj.GetModule("mockup_restapiserver"))
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
+
+func TestDoWireWithoutCloser(t *testing.T) {
+	wj := New()
+	wj.AddEagerInjector("test_client", func() *testClient { return &testClient{} })
+	wj.SetActivatingModules([]string{"test_client"})
+
+	err := wj.DoWire()
+	assert.NoError(t, err, "Failed to DoWire()")
+	assert.IsType(t, &testClient{}, wj.GetModule("test_client"))
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
 }
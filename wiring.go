@@ -0,0 +1,248 @@
+package wirejacket
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/bang9211/wire-jacket/internal/utils"
+)
+
+// wiringNode is a module to create in a wiring plan.
+// dependencyNames are the modules providing each parameter of injector.
+// dependencyPath is the chain of modules from the requested module to
+// this module. moduleType is the type injector returns.
+// withContext is true if injector gets context.Context.
+// attempt is the wiring attempt the node is created in, see Span.
+type wiringNode struct {
+	moduleName      string
+	moduleType      reflect.Type
+	injector        interface{}
+	dependencyTypes []reflect.Type
+	dependencyNames []string
+	dependencyPath  []string
+	withContext     bool
+	attempt         int
+}
+
+// buildWiringPlan returns the modules to create for moduleNames,
+// using only the injector signatures. No injector is called.
+// The modules are sorted topologically, dependencies come before
+// the modules using them and the order of activating modules breaks ties.
+// Modules already created are not included.
+// It returns CycleError if the injectors depend on each other.
+// wj.mu should be held.
+func (wj *WireJacket) buildWiringPlan(moduleNames []string) ([]*wiringNode, error) {
+	nodes := map[string]*wiringNode{}
+	visiting := map[string]bool{}
+	path := []string{}
+	pathTypes := []reflect.Type{}
+	var visit func(moduleName string) error
+	visit = func(moduleName string) error {
+		if wj.modules[moduleName] != nil {
+			return nil
+		}
+		if visiting[moduleName] {
+			return newCycleError(path, pathTypes, moduleName)
+		}
+		if nodes[moduleName] != nil {
+			return nil
+		}
+		path = append(path, moduleName)
+		defer func() { path = path[:len(path)-1] }()
+
+		node, err := wj.newWiringNode(moduleName)
+		if err != nil {
+			return &InjectionError{
+				Module:         moduleName,
+				DependencyPath: append([]string{}, path...),
+				Cause:          err,
+			}
+		}
+		node.dependencyPath = append([]string{}, path...)
+		nodes[moduleName] = node
+
+		visiting[moduleName] = true
+		for i, dependencyName := range node.dependencyNames {
+			pathTypes = append(pathTypes, node.dependencyTypes[i])
+			err := visit(dependencyName)
+			pathTypes = pathTypes[:len(pathTypes)-1]
+			if err != nil {
+				return err
+			}
+		}
+		visiting[moduleName] = false
+
+		return nil
+	}
+	for _, moduleName := range moduleNames {
+		if err := visit(moduleName); err != nil {
+			return nil, err
+		}
+	}
+
+	return wj.sortWiringNodes(nodes)
+}
+
+// newWiringNode resolves the providers of the parameters of
+// the injector of moduleName.
+func (wj *WireJacket) newWiringNode(moduleName string) (*wiringNode, error) {
+	injector := wj.getInjector(moduleName)
+	if injector == nil {
+		return nil, fmt.Errorf("%w : no injector of module(%s)",
+			ErrNoInjector, moduleName)
+	}
+	if !utils.IsContain(wj.activatingModuleNames, moduleName) {
+		return nil, fmt.Errorf("%w : no activating module name for injector(%s), in %s",
+			ErrModuleNotActivated,
+			moduleName,
+			wj.activatingModuleNames)
+	}
+	injectorFuncType := reflect.TypeOf(injector)
+	if err := checkInjectorSignature(injectorFuncType); err != nil {
+		return nil, err
+	}
+
+	node := &wiringNode{
+		moduleName:  moduleName,
+		moduleType:  injectorFuncType.Out(0),
+		injector:    injector,
+		withContext: hasContextParam(injectorFuncType),
+	}
+	for _, dependencyType := range wj.getDependencyTypeList(injectorFuncType) {
+		dependencyName := wj.findProvider(dependencyType)
+		if dependencyName == "" {
+			return nil, fmt.Errorf("%w : no activated injector of dependency(%s)",
+				ErrNoInjector, dependencyType)
+		}
+		node.dependencyTypes = append(node.dependencyTypes, dependencyType)
+		node.dependencyNames = append(node.dependencyNames, dependencyName)
+	}
+
+	return node, nil
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+var cleanupType = reflect.TypeOf((func())(nil))
+
+// checkInjectorSignature checks the return types of injector.
+// Injector should return (module), (module, error), (module, cleanup)
+// or (module, cleanup, error) like the injectors generated by wire.
+func checkInjectorSignature(injectorFuncType reflect.Type) error {
+	if injectorFuncType.Kind() != reflect.Func {
+		return fmt.Errorf("%w : injector(%s) is not function",
+			ErrInvalidInjectorSignature, injectorFuncType)
+	}
+	switch injectorFuncType.NumOut() {
+	case 1:
+		return nil
+	case 2:
+		if injectorFuncType.Out(1) == errorType ||
+			injectorFuncType.Out(1) == cleanupType {
+			return nil
+		}
+	case 3:
+		if injectorFuncType.Out(1) == cleanupType &&
+			injectorFuncType.Out(2) == errorType {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w : injector(%s) should return (module), (module, error), "+
+		"(module, func()) or (module, func(), error)",
+		ErrInvalidInjectorSignature, injectorFuncType)
+}
+
+// sortWiringNodes sorts nodes topologically. Among the nodes whose
+// dependencies are all sorted, the first in the activating modules
+// comes first.
+func (wj *WireJacket) sortWiringNodes(nodes map[string]*wiringNode) ([]*wiringNode, error) {
+	moduleNames := []string{}
+	for moduleName := range nodes {
+		moduleNames = append(moduleNames, moduleName)
+	}
+	wj.sortByActivatingOrder(moduleNames)
+
+	waitings := map[string]int{}
+	dependents := map[string][]string{}
+	for _, moduleName := range moduleNames {
+		for _, dependencyName := range nodes[moduleName].dependencyNames {
+			if nodes[dependencyName] != nil {
+				waitings[moduleName]++
+				dependents[dependencyName] = append(dependents[dependencyName], moduleName)
+			}
+		}
+	}
+
+	sorted := []*wiringNode{}
+	done := map[string]bool{}
+	for len(sorted) < len(moduleNames) {
+		next := ""
+		for _, moduleName := range moduleNames {
+			if !done[moduleName] && waitings[moduleName] == 0 {
+				next = moduleName
+				break
+			}
+		}
+		if next == "" {
+			return nil, fmt.Errorf("dependency cycle between modules")
+		}
+		done[next] = true
+		sorted = append(sorted, nodes[next])
+		for _, dependentName := range dependents[next] {
+			waitings[dependentName]--
+		}
+	}
+
+	return sorted, nil
+}
+
+// findProvider finds the name of activated module providing dependencyType.
+// Created modules are preferred over injectors and the order of
+// activating modules breaks ties. It returns "" if there is no provider.
+// wj.mu should be held.
+func (wj *WireJacket) findProvider(dependencyType reflect.Type) string {
+	for _, moduleName := range wj.activatingModuleNames {
+		module := wj.modules[moduleName]
+		if module != nil && reflect.ValueOf(module).CanConvert(dependencyType) {
+			return moduleName
+		}
+	}
+	for _, moduleName := range wj.activatingModuleNames {
+		if wj.modules[moduleName] != nil {
+			continue
+		}
+		injector := wj.getInjector(moduleName)
+		if injector == nil {
+			continue
+		}
+		injectorFuncType := reflect.TypeOf(injector)
+		if injectorFuncType.NumOut() > 0 &&
+			injectorFuncType.Out(0) == dependencyType {
+			return moduleName
+		}
+	}
+
+	return ""
+}
+
+// sortByActivatingOrder sorts moduleNames by the order of activating
+// modules. Module names not activated are sorted by name at the end.
+func (wj *WireJacket) sortByActivatingOrder(moduleNames []string) {
+	index := map[string]int{}
+	for i, moduleName := range wj.activatingModuleNames {
+		if _, ok := index[moduleName]; !ok {
+			index[moduleName] = i
+		}
+	}
+	sort.SliceStable(moduleNames, func(i, j int) bool {
+		iIndex, iOk := index[moduleNames[i]]
+		jIndex, jOk := index[moduleNames[j]]
+		if iOk != jOk {
+			return iOk
+		}
+		if iOk && iIndex != jIndex {
+			return iIndex < jIndex
+		}
+		return moduleNames[i] < moduleNames[j]
+	})
+}
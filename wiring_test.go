@@ -0,0 +1,142 @@
+package wirejacket
+
+import (
+	"errors"
+	"testing"
+
+	viperjacket "github.com/bang9211/viper-jacket"
+	"github.com/stretchr/testify/assert"
+)
+
+func injectOtherTestDatabase(config viperjacket.Config) (testDatabase, error) {
+	return &testDatabaseImpl{closeRecorder{"other_test_database"}}, nil
+}
+
+func injectOtherTestServer(config viperjacket.Config, blockchain testBlockchain) (testServer, error) {
+	return &testServerImpl{closeRecorder{"other_test_server"}}, nil
+}
+
+func newDeterministicTestWireJacket(activatingModuleNames []string) *WireJacket {
+	wj := New()
+	wj.AddInjector("test_database", injectTestDatabase)
+	wj.AddInjector("other_test_database", injectOtherTestDatabase)
+	wj.AddInjector("test_blockchain", injectTestBlockchain)
+	wj.AddEagerInjector("test_server", injectTestServer)
+	wj.AddEagerInjector("other_test_server", injectOtherTestServer)
+	wj.SetActivatingModules(activatingModuleNames)
+	return wj
+}
+
+func TestDoWireDeterministicOrder(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		wj := newDeterministicTestWireJacket([]string{
+			"other_test_server",
+			"test_server",
+			"test_blockchain",
+			"other_test_database",
+			"test_database",
+		})
+
+		err := wj.DoWire()
+		assert.NoError(t, err, "Failed to DoWire()")
+		assert.Equal(t, []string{
+			DefaultConfigName,
+			"other_test_database",
+			"test_blockchain",
+			"other_test_server",
+			"test_server",
+		}, wj.createdModuleNames)
+		assert.Equal(t, []string{"other_test_database"}, wj.dependencies["test_blockchain"])
+		assert.NotContains(t, wj.modules, "test_database")
+
+		err = wj.Close()
+		assert.NoError(t, err, "Failed to Close()")
+	}
+}
+
+func TestBuildWiringPlan(t *testing.T) {
+	wj := newDeterministicTestWireJacket([]string{
+		"test_database",
+		"other_test_database",
+		"test_blockchain",
+		"test_server",
+		"other_test_server",
+	})
+
+	plan, err := wj.buildWiringPlan([]string{"other_test_server", "test_server"})
+	assert.NoError(t, err)
+	moduleNames := []string{}
+	for _, node := range plan {
+		moduleNames = append(moduleNames, node.moduleName)
+	}
+	assert.Equal(t, []string{
+		"test_database",
+		"test_blockchain",
+		"test_server",
+		"other_test_server",
+	}, moduleNames)
+	assert.Equal(t, []string{DefaultConfigName, "test_blockchain"}, plan[2].dependencyNames)
+	assert.Empty(t, wj.createdModuleNames[1:], "no injector should be called")
+
+	_, err = wj.buildWiringPlan([]string{"no_exists"})
+	assert.Error(t, err)
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
+
+func TestSortByActivatingOrder(t *testing.T) {
+	wj := New()
+	wj.SetActivatingModules([]string{"c", "a", "b"})
+
+	moduleNames := []string{"z", "a", "b", "y", "c"}
+	wj.sortByActivatingOrder(moduleNames)
+	assert.Equal(t, []string{"c", "a", "b", "y", "z"}, moduleNames)
+}
+
+func injectCyclicTestBlockchain(server testServer) (testBlockchain, error) {
+	return &testBlockchainImpl{closeRecorder{"test_blockchain"}}, nil
+}
+
+func injectSelfCyclicTestDatabase(db testDatabase) (testDatabase, error) {
+	return &testDatabaseImpl{closeRecorder{"test_database"}}, nil
+}
+
+func TestDoWireDependencyCycle(t *testing.T) {
+	wj := New()
+	wj.AddInjector("test_blockchain", injectCyclicTestBlockchain)
+	wj.AddEagerInjector("test_server", injectTestServer)
+	wj.SetActivatingModules([]string{"test_blockchain", "test_server"})
+
+	resetClosedModuleNames()
+	err := wj.DoWire()
+	var cycleErr *CycleError
+	assert.True(t, errors.As(err, &cycleErr))
+	assert.Equal(t, []string{"test_server", "test_blockchain", "test_server"}, cycleErr.Path)
+	assert.Equal(t,
+		"dependency cycle : test_server -> test_blockchain -> test_server "+
+			"(test_server needs wirejacket.testBlockchain, "+
+			"test_blockchain needs wirejacket.testServer)",
+		err.Error())
+	assert.Equal(t, []string{DefaultConfigName}, wj.createdModuleNames, "no injector should be called")
+	assert.Empty(t, getClosedModuleNames())
+
+	assert.Nil(t, wj.GetModule("test_blockchain"))
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}
+
+func TestBuildWiringPlanSelfCycle(t *testing.T) {
+	wj := New()
+	wj.AddInjector("test_database", injectSelfCyclicTestDatabase)
+	wj.SetActivatingModules([]string{"test_database"})
+
+	_, err := wj.buildWiringPlan([]string{"test_database"})
+	var cycleErr *CycleError
+	assert.True(t, errors.As(err, &cycleErr))
+	assert.Equal(t, []string{"test_database", "test_database"}, cycleErr.Path)
+
+	err = wj.Close()
+	assert.NoError(t, err, "Failed to Close()")
+}